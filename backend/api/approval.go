@@ -0,0 +1,199 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ApprovalHandler struct {
+	assignmentService *services.AssignmentService
+	historyService    *services.HistoryService
+	outboxService     *services.OutboxService
+	workflowService   *services.WorkflowService
+}
+
+func NewApprovalHandler() *ApprovalHandler {
+	settingsService := services.NewSettingsService()
+	return &ApprovalHandler{
+		assignmentService: services.NewAssignmentService(settingsService),
+		historyService:    services.NewHistoryService(),
+		outboxService:     services.NewOutboxService(settingsService),
+		workflowService:   services.NewWorkflowService(),
+	}
+}
+
+// errNotAwaitingApproval aborts a decision that lost the race with another approver
+var errNotAwaitingApproval = errors.New("repair request is not awaiting approval")
+
+type ApprovalRequest struct {
+	Approved *bool  `json:"approved" binding:"required"`
+	Note     string `json:"note"`
+}
+
+// DecideApproval handles POST /api/repair-requests/:id/approval
+func (h *ApprovalHandler) DecideApproval(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair request ID"})
+		return
+	}
+
+	var request models.RepairRequest
+	if err := config.DB.Preload("Category").Preload("Requester").First(&request, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+		return
+	}
+
+	// Only admins and the category's designated approver may decide
+	user, _ := currentUser(c)
+	if !h.canApprove(user, &request.Category) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to approve requests in this category"})
+		return
+	}
+
+	if request.Status != models.StatusAwaitingApproval {
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request is not awaiting approval", "status": request.Status})
+		return
+	}
+
+	var req ApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if !*req.Approved && req.Note == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A note is required when rejecting a request"})
+		return
+	}
+
+	approval := models.Approval{
+		RepairRequestID: request.ID,
+		ApproverID:      user.ID,
+		Approved:        *req.Approved,
+		Note:            req.Note,
+	}
+	oldStatus := string(request.Status)
+	if approval.Approved {
+		// An approved appeal must not keep the earlier rejection
+		request.Status = models.StatusPending
+		request.RejectionReason = ""
+		request.RejectedByID = nil
+		request.RejectedAt = nil
+	} else {
+		now := time.Now()
+		request.Status = models.StatusRejected
+		request.RejectionReason = req.Note
+		request.RejectedByID = &user.ID
+		request.RejectedAt = &now
+	}
+
+	// The decision takes an approval edge of the workflow like any other status change
+	approver := request.Category.ApproverID != nil && *request.Category.ApproverID == user.ID
+	var transitionErr *services.TransitionError
+	if err := h.workflowService.ValidateApproval(models.RepairStatus(oldStatus), &request, user.Role, approver); errors.As(err, &transitionErr) {
+		status := http.StatusConflict
+		if len(transitionErr.Missing) > 0 {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+			"missing": transitionErr.Missing,
+		})
+		return
+	}
+
+	columns := map[string]interface{}{
+		"status":           request.Status,
+		"rejection_reason": request.RejectionReason,
+		"rejected_by_id":   request.RejectedByID,
+		"rejected_at":      request.RejectedAt,
+		"version":          gorm.Expr("version + 1"),
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&approval).Error; err != nil {
+			return err
+		}
+		// Only the first of two concurrent decisions may move the request on
+		result := tx.Model(&models.RepairRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.StatusAwaitingApproval).
+			Updates(columns)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNotAwaitingApproval
+		}
+		request.Version++
+		if err := h.historyService.RecordChange(tx, request.ID, &user.ID, services.HistoryFieldStatus, oldStatus, string(request.Status)); err != nil {
+			return err
+		}
+		return h.outboxService.Enqueue(tx, models.EventRequestApproval, models.NotificationPayload{
+			RepairRequestID: request.ID,
+			ActorID:         &user.ID,
+			OldStatus:       oldStatus,
+			ApprovalID:      approval.ID,
+		})
+	})
+	if errors.Is(err, errNotAwaitingApproval) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request is not awaiting approval"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record approval"})
+		return
+	}
+	approval.Approver = user
+
+	// Approved requests enter the technician queue, so try to assign one now
+	if approval.Approved {
+		if _, err := h.assignmentService.AutoAssign(&request, nil); err != nil {
+			log.Printf("Warning: Failed to auto-assign repair request %d: %v", request.ID, err)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"request":  request,
+		"approval": approval,
+	})
+}
+
+// ListPendingApprovals handles GET /api/approvals
+func (h *ApprovalHandler) ListPendingApprovals(c *gin.Context) {
+	user, _ := currentUser(c)
+
+	query := config.DB.Preload("Category").Preload("Requester").
+		Where("status = ?", models.StatusAwaitingApproval)
+	if user.Role != models.RoleAdmin {
+		query = query.Where("category_id IN (SELECT id FROM categories WHERE approver_id = ? AND deleted_at IS NULL)", user.ID)
+	}
+
+	requests := []models.RepairRequest{}
+	if err := query.Order("created_at ASC").Find(&requests).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pending approvals"})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+func (h *ApprovalHandler) canApprove(user models.User, category *models.Category) bool {
+	if user.Role == models.RoleAdmin {
+		return true
+	}
+	return category.ApproverID != nil && *category.ApproverID == user.ID
+}
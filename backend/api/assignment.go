@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssignmentHandler struct {
+	assignmentService *services.AssignmentService
+}
+
+func NewAssignmentHandler() *AssignmentHandler {
+	settingsService := services.NewSettingsService()
+	return &AssignmentHandler{
+		assignmentService: services.NewAssignmentService(settingsService),
+	}
+}
+
+type AssignRequest struct {
+	TechnicianID *uint  `json:"technicianId"` // Overrides the engine when set
+	Strategy     string `json:"strategy"`
+}
+
+// PreviewAssignment handles GET /api/repair-requests/:id/assignment/preview
+func (h *AssignmentHandler) PreviewAssignment(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	technician, strategy, err := h.assignmentService.Preview(request, c.Query("strategy"))
+	if err != nil {
+		h.respondWithAssignmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"strategy":   strategy,
+		"strategies": h.assignmentService.Strategies(),
+		"technician": technician,
+	})
+}
+
+// AssignTechnician handles POST /api/repair-requests/:id/assign
+func (h *AssignmentHandler) AssignTechnician(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB.Preload("Category").Preload("Requester"))
+	if !ok {
+		return
+	}
+
+	switch request.Status {
+	case models.StatusPending, models.StatusInProgress, models.StatusWaitingPart:
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request cannot be assigned in its current status", "status": request.Status})
+		return
+	}
+
+	var req AssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var technician *models.User
+	if req.TechnicianID != nil {
+		technician = &models.User{}
+		if err := config.DB.First(technician, *req.TechnicianID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Technician not found"})
+			return
+		}
+		if technician.Role != models.RoleTechnician && technician.Role != models.RoleAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "User cannot be assigned repair requests"})
+			return
+		}
+	} else {
+		var err error
+		technician, _, err = h.assignmentService.Preview(request, req.Strategy)
+		if err != nil {
+			h.respondWithAssignmentError(c, err)
+			return
+		}
+	}
+
+	user, _ := currentUser(c)
+	if err := h.assignmentService.Assign(request, technician, &user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign technician"})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+func (h *AssignmentHandler) respondWithAssignmentError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrNoTechnicianAvailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No available technician"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -50,6 +50,10 @@ func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	if !h.validApprover(c, category.ApproverID) {
+		return
+	}
+
 	if err := config.DB.Create(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category"})
 		return
@@ -82,6 +86,11 @@ func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 		category.Name = updateData.Name
 	}
 	category.Description = updateData.Description
+	category.ApproverID = updateData.ApproverID
+
+	if !h.validApprover(c, category.ApproverID) {
+		return
+	}
 
 	if err := config.DB.Save(&category).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
@@ -118,3 +127,17 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
 }
+
+// validApprover checks that an optional category approver refers to an existing user
+func (h *CategoryHandler) validApprover(c *gin.Context, approverID *uint) bool {
+	if approverID == nil {
+		return true
+	}
+
+	var approver models.User
+	if err := config.DB.First(&approver, *approverID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Approver not found"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CommentHandler struct {
+	outboxService *services.OutboxService
+}
+
+func NewCommentHandler() *CommentHandler {
+	return &CommentHandler{
+		outboxService: services.NewOutboxService(services.NewSettingsService()),
+	}
+}
+
+type CommentRequest struct {
+	Content    string `json:"content" binding:"required"`
+	IsInternal bool   `json:"isInternal"`
+}
+
+// visibleComments limits a comment query to what the given user is allowed to see
+func visibleComments(db *gorm.DB, user models.User) *gorm.DB {
+	if user.Role == models.RoleRequester {
+		return db.Where("is_internal = ?", false)
+	}
+	return db
+}
+
+// ListComments handles GET /api/repair-requests/:id/comments
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	var comments []models.Comment
+	query := visibleComments(config.DB.Preload("User").Where("repair_request_id = ?", request.ID), user)
+	if err := query.Order("created_at ASC").Find(&comments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments"})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// CreateComment handles POST /api/repair-requests/:id/comments
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment content is required"})
+		return
+	}
+
+	user, _ := currentUser(c)
+	if req.IsInternal && user.Role == models.RoleRequester {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only technicians and admins can post internal notes"})
+		return
+	}
+
+	comment := models.Comment{
+		RepairRequestID: request.ID,
+		UserID:          user.ID,
+		Content:         req.Content,
+		IsInternal:      req.IsInternal,
+	}
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&comment).Error; err != nil {
+			return err
+		}
+		// Notify the other party; internal notes never leave the staff side
+		if comment.IsInternal {
+			return nil
+		}
+		return h.outboxService.Enqueue(tx, models.EventCommentAdded, models.NotificationPayload{
+			RepairRequestID: request.ID,
+			ActorID:         &user.ID,
+			CommentID:       comment.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create comment"})
+		return
+	}
+	comment.User = user
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// UpdateComment handles PUT /api/repair-requests/:id/comments/:commentId
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	comment, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	if comment.UserID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can edit this comment"})
+		return
+	}
+
+	var req CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Comment content is required"})
+		return
+	}
+	if req.IsInternal && user.Role == models.RoleRequester {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only technicians and admins can post internal notes"})
+		return
+	}
+
+	wasInternal := comment.IsInternal
+	comment.Content = req.Content
+	comment.IsInternal = req.IsInternal
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		// Update only the edited columns; Save would also write back the preloaded author
+		if err := tx.Model(&comment).Updates(map[string]interface{}{
+			"content":     comment.Content,
+			"is_internal": comment.IsInternal,
+		}).Error; err != nil {
+			return err
+		}
+		// A note made public reaches the other party for the first time
+		if !wasInternal || comment.IsInternal {
+			return nil
+		}
+		return h.outboxService.Enqueue(tx, models.EventCommentAdded, models.NotificationPayload{
+			RepairRequestID: comment.RepairRequestID,
+			ActorID:         &user.ID,
+			CommentID:       comment.ID,
+		})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update comment"})
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment handles DELETE /api/repair-requests/:id/comments/:commentId
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	comment, ok := h.loadComment(c)
+	if !ok {
+		return
+	}
+
+	// Authors can remove their own comments, admins can moderate any comment
+	user, _ := currentUser(c)
+	if comment.UserID != user.ID && user.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author or an admin can delete this comment"})
+		return
+	}
+
+	if err := config.DB.Delete(&comment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted successfully"})
+}
+
+// loadRepairRequest fetches the repair request named by the :id parameter, writing an error response on failure
+func (h *CommentHandler) loadRepairRequest(c *gin.Context) (*models.RepairRequest, bool) {
+	return findVisibleRepairRequest(c, config.DB.Preload("Requester").Preload("Technician"))
+}
+
+// loadComment fetches the comment named by the :commentId parameter within the :id repair request
+func (h *CommentHandler) loadComment(c *gin.Context) (models.Comment, bool) {
+	var comment models.Comment
+
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return comment, false
+	}
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return comment, false
+	}
+
+	user, _ := currentUser(c)
+	query := visibleComments(config.DB.Preload("User").Where("repair_request_id = ?", request.ID), user)
+	if err := query.First(&comment, commentID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return comment, false
+	}
+	return comment, true
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentUser returns the authenticated user stored in the context by AuthMiddleware
+func currentUser(c *gin.Context) (models.User, bool) {
+	userValue, exists := c.Get("user")
+	if !exists {
+		return models.User{}, false
+	}
+	user, ok := userValue.(models.User)
+	return user, ok
+}
+
+// findVisibleRepairRequest loads the repair request named by the :id parameter through query,
+// scoped to what the current user may see. It writes the error response and returns false
+// when the ID is invalid or the request is not visible.
+func findVisibleRepairRequest(c *gin.Context, query *gorm.DB) (*models.RepairRequest, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair request ID"})
+		return nil, false
+	}
+
+	user, _ := currentUser(c)
+	var request models.RepairRequest
+	if err := query.Scopes(services.VisibleRepairRequests(user)).First(&request, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+		return nil, false
+	}
+	return &request, true
+}
+
+// setETag exposes the request version as a strong ETag
+func setETag(c *gin.Context, request *models.RepairRequest) {
+	c.Header("ETag", `"`+strconv.FormatUint(uint64(request.Version), 10)+`"`)
+}
+
+// expectedVersion returns the version the client based its update on, taken from the
+// If-Match header or else the version field of the body. Zero means "not given".
+func expectedVersion(c *gin.Context, bodyVersion uint) (uint, error) {
+	ifMatch := strings.TrimSpace(c.GetHeader("If-Match"))
+	if ifMatch == "" || ifMatch == "*" {
+		return bodyVersion, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(ifMatch, "W/"), `"`)
+	version, err := strconv.ParseUint(tag, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid If-Match header")
+	}
+	return uint(version), nil
+}
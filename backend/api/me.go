@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeHandler serves settings that users manage for their own account
+type MeHandler struct {
+	telegramBotService *services.TelegramBotService
+}
+
+func NewMeHandler() *MeHandler {
+	return &MeHandler{
+		telegramBotService: services.NewTelegramBotService(services.NewSettingsService()),
+	}
+}
+
+type NotificationPreferences struct {
+	TelegramLinked bool `json:"telegramLinked"`
+	TelegramDMs    bool `json:"telegramDms"`
+}
+
+// GetNotificationPreferences handles GET /api/me/notifications
+func (h *MeHandler) GetNotificationPreferences(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, h.preferences(user))
+}
+
+// UpdateNotificationPreferences handles PUT /api/me/notifications
+func (h *MeHandler) UpdateNotificationPreferences(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		TelegramDMs *bool `json:"telegramDms" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.DB.Model(user).Update("telegram_dms", *req.TelegramDMs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+	c.JSON(http.StatusOK, h.preferences(user))
+}
+
+// CreateTelegramLink handles POST /api/me/telegram/link
+func (h *MeHandler) CreateTelegramLink(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	linkCode, url, err := h.telegramBotService.CreateLinkCode(user)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to create Telegram link: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"code":      linkCode.Code,
+		"url":       url,
+		"expiresAt": linkCode.ExpiresAt,
+	})
+}
+
+// UnlinkTelegram handles DELETE /api/me/telegram
+func (h *MeHandler) UnlinkTelegram(c *gin.Context) {
+	user, ok := h.loadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.telegramBotService.Unlink(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlink Telegram"})
+		return
+	}
+	c.JSON(http.StatusOK, h.preferences(user))
+}
+
+func (h *MeHandler) preferences(user *models.User) NotificationPreferences {
+	return NotificationPreferences{
+		TelegramLinked: user.TelegramID != "",
+		TelegramDMs:    user.TelegramDMs,
+	}
+}
+
+// loadUser reloads the signed-in user so preferences reflect the latest saved values
+func (h *MeHandler) loadUser(c *gin.Context) (*models.User, bool) {
+	current, _ := currentUser(c)
+
+	var user models.User
+	if err := config.DB.First(&user, current.ID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return nil, false
+	}
+	return &user, true
+}
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	outboxService *services.OutboxService
+}
+
+func NewNotificationHandler() *NotificationHandler {
+	return &NotificationHandler{
+		outboxService: services.NewOutboxService(services.NewSettingsService()),
+	}
+}
+
+// ListOutbox handles GET /api/notifications/outbox
+func (h *NotificationHandler) ListOutbox(c *gin.Context) {
+	status := models.OutboxStatus(c.Query("status"))
+	switch status {
+	case "", models.OutboxPending, models.OutboxSent, models.OutboxDead, models.OutboxCancelled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	limit := services.DefaultPageLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > services.MaxPageLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	messages, err := h.outboxService.List(status, c.Query("channel"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	c.JSON(http.StatusOK, messages)
+}
+
+// ReplayOutbox handles POST /api/notifications/outbox/:id/replay
+func (h *NotificationHandler) ReplayOutbox(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	message, err := h.outboxService.Replay(uint(id))
+	if errors.Is(err, services.ErrNotReplayable) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Only dead or cancelled notifications can be replayed", "status": message.Status})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	c.JSON(http.StatusOK, message)
+}
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationTemplateHandler struct {
+	templateService *services.NotificationTemplateService
+}
+
+func NewNotificationTemplateHandler() *NotificationTemplateHandler {
+	return &NotificationTemplateHandler{
+		templateService: services.NewNotificationTemplateService(services.NewSettingsService()),
+	}
+}
+
+type NotificationTemplateRequest struct {
+	Subject string `json:"subject"` // Email only
+	Body    string `json:"body" binding:"required"`
+	HTML    string `json:"html"` // Email only
+}
+
+type NotificationTemplatePreviewRequest struct {
+	Channel   string `json:"channel" binding:"required"`
+	Event     string `json:"event" binding:"required"`
+	Locale    string `json:"locale" binding:"required"`
+	Subject   string `json:"subject"`
+	Body      string `json:"body"` // Previews the current template when empty
+	HTML      string `json:"html"`
+	RequestID *uint  `json:"requestId"` // Renders against a made-up request when omitted
+}
+
+// ListNotificationTemplates handles GET /api/notification-templates
+func (h *NotificationTemplateHandler) ListNotificationTemplates(c *gin.Context) {
+	templates, err := h.templateService.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"channels":  services.NotificationTemplateChannels,
+		"events":    services.NotificationEvents,
+		"locales":   services.NotificationLocales,
+		"locale":    h.templateService.Locale(),
+	})
+}
+
+// UpdateNotificationTemplate handles PUT /api/notification-templates/:channel/:event/:locale
+func (h *NotificationTemplateHandler) UpdateNotificationTemplate(c *gin.Context) {
+	var req NotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template := models.NotificationTemplate{
+		Channel: c.Param("channel"),
+		Event:   c.Param("event"),
+		Locale:  c.Param("locale"),
+		Subject: req.Subject,
+		Body:    req.Body,
+		HTML:    req.HTML,
+	}
+	if err := h.templateService.Save(&template); err != nil {
+		h.respondWithTemplateError(c, err, "Failed to save notification template")
+		return
+	}
+
+	c.JSON(http.StatusOK, services.NotificationTemplateView{NotificationTemplate: template, Customized: true})
+}
+
+// ResetNotificationTemplate handles DELETE /api/notification-templates/:channel/:event/:locale
+func (h *NotificationTemplateHandler) ResetNotificationTemplate(c *gin.Context) {
+	channel, event, locale := c.Param("channel"), c.Param("event"), c.Param("locale")
+	if err := h.templateService.Reset(channel, event, locale); err != nil {
+		h.respondWithTemplateError(c, err, "Failed to reset notification template")
+		return
+	}
+
+	template, err := h.templateService.Get(channel, event, locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notification template"})
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// PreviewNotificationTemplate handles POST /api/notification-templates/preview
+func (h *NotificationTemplateHandler) PreviewNotificationTemplate(c *gin.Context) {
+	var req NotificationTemplatePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := h.templateService.Get(req.Channel, req.Event, req.Locale)
+	if err != nil {
+		h.respondWithTemplateError(c, err, "Failed to fetch notification template")
+		return
+	}
+	template := current.NotificationTemplate
+	if req.Body != "" {
+		template.Subject, template.Body, template.HTML = req.Subject, req.Body, req.HTML
+	}
+
+	var request *models.RepairRequest
+	if req.RequestID != nil {
+		request = &models.RepairRequest{}
+		err := config.DB.Unscoped().Preload("Category").Preload("Requester").Preload("Technician").
+			First(request, *req.RequestID).Error
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+			return
+		}
+	}
+
+	rendered, err := h.templateService.Render(&template, h.templateService.SampleData(req.Event, req.Locale, request))
+	if err != nil {
+		h.respondWithTemplateError(c, err, "Failed to render notification template")
+		return
+	}
+	c.JSON(http.StatusOK, rendered)
+}
+
+func (h *NotificationTemplateHandler) respondWithTemplateError(c *gin.Context, err error, message string) {
+	var templateErr *services.NotificationTemplateError
+	switch {
+	case errors.Is(err, services.ErrUnknownNotificationTemplate):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown channel, event or locale"})
+	case errors.As(err, &templateErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Invalid template", "field": templateErr.Field, "details": templateErr.Err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
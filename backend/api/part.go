@@ -0,0 +1,255 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PartHandler struct {
+	costService    *services.CostService
+	historyService *services.HistoryService
+}
+
+func NewPartHandler() *PartHandler {
+	return &PartHandler{
+		costService:    services.NewCostService(),
+		historyService: services.NewHistoryService(),
+	}
+}
+
+type PartRequest struct {
+	Name      string  `json:"name" binding:"required"`
+	Quantity  int     `json:"quantity" binding:"required,min=1"`
+	UnitPrice float64 `json:"unitPrice" binding:"min=0"`
+}
+
+type CostRequest struct {
+	LaborCost float64 `json:"laborCost" binding:"min=0"`
+	OtherCost float64 `json:"otherCost" binding:"min=0"`
+}
+
+// ListParts handles GET /api/repair-requests/:id/parts
+func (h *PartHandler) ListParts(c *gin.Context) {
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	var parts []models.PartUsed
+	if err := config.DB.Where("repair_request_id = ?", request.ID).Order("id ASC").Find(&parts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch parts"})
+		return
+	}
+	c.JSON(http.StatusOK, parts)
+}
+
+// GetCost handles GET /api/repair-requests/:id/cost
+func (h *PartHandler) GetCost(c *gin.Context) {
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	breakdown, err := h.costService.Breakdown(config.DB, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate cost"})
+		return
+	}
+	c.JSON(http.StatusOK, breakdown)
+}
+
+// UpdateCost handles PUT /api/repair-requests/:id/cost
+func (h *PartHandler) UpdateCost(c *gin.Context) {
+	request, ok := h.loadEditableRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	var req CostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	request.LaborCost = req.LaborCost
+	request.OtherCost = req.OtherCost
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RepairRequest{}).Where("id = ?", request.ID).Updates(map[string]interface{}{
+			"labor_cost": request.LaborCost,
+			"other_cost": request.OtherCost,
+		}).Error; err != nil {
+			return err
+		}
+		return h.recalculateCost(c, tx, request)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cost"})
+		return
+	}
+
+	h.respondWithBreakdown(c, http.StatusOK, request)
+}
+
+// CreatePart handles POST /api/repair-requests/:id/parts
+func (h *PartHandler) CreatePart(c *gin.Context) {
+	request, ok := h.loadEditableRepairRequest(c)
+	if !ok {
+		return
+	}
+
+	var req PartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	part := models.PartUsed{
+		RepairRequestID: request.ID,
+		Name:            req.Name,
+		Quantity:        req.Quantity,
+		UnitPrice:       req.UnitPrice,
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&part).Error; err != nil {
+			return err
+		}
+		return h.recalculateCost(c, tx, request)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add part"})
+		return
+	}
+
+	h.respondWithBreakdown(c, http.StatusCreated, request)
+}
+
+// UpdatePart handles PUT /api/repair-requests/:id/parts/:partId
+func (h *PartHandler) UpdatePart(c *gin.Context) {
+	request, ok := h.loadEditableRepairRequest(c)
+	if !ok {
+		return
+	}
+	part, ok := h.loadPart(c, request)
+	if !ok {
+		return
+	}
+
+	var req PartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	part.Name = req.Name
+	part.Quantity = req.Quantity
+	part.UnitPrice = req.UnitPrice
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&part).Error; err != nil {
+			return err
+		}
+		return h.recalculateCost(c, tx, request)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update part"})
+		return
+	}
+
+	h.respondWithBreakdown(c, http.StatusOK, request)
+}
+
+// DeletePart handles DELETE /api/repair-requests/:id/parts/:partId
+func (h *PartHandler) DeletePart(c *gin.Context) {
+	request, ok := h.loadEditableRepairRequest(c)
+	if !ok {
+		return
+	}
+	part, ok := h.loadPart(c, request)
+	if !ok {
+		return
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&part).Error; err != nil {
+			return err
+		}
+		return h.recalculateCost(c, tx, request)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete part"})
+		return
+	}
+
+	h.respondWithBreakdown(c, http.StatusOK, request)
+}
+
+// recalculateCost updates the request's cost and version inside tx and records the change in its history
+func (h *PartHandler) recalculateCost(c *gin.Context, tx *gorm.DB, request *models.RepairRequest) error {
+	oldCost := request.Cost
+	if err := h.costService.Recalculate(tx, request); err != nil {
+		return err
+	}
+	if err := services.UpdateRepairRequestColumns(tx, request, map[string]interface{}{}); err != nil {
+		return err
+	}
+
+	user, _ := currentUser(c)
+	return h.historyService.RecordChange(tx, request.ID, &user.ID, services.HistoryFieldCost,
+		h.historyService.FormatCost(oldCost), h.historyService.FormatCost(request.Cost))
+}
+
+// respondWithBreakdown writes the current cost breakdown of the request
+func (h *PartHandler) respondWithBreakdown(c *gin.Context, status int, request *models.RepairRequest) {
+	breakdown, err := h.costService.Breakdown(config.DB, request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate cost"})
+		return
+	}
+	c.JSON(status, breakdown)
+}
+
+// loadRepairRequest fetches the repair request named by the :id parameter, writing an error response on failure
+func (h *PartHandler) loadRepairRequest(c *gin.Context) (*models.RepairRequest, bool) {
+	return findVisibleRepairRequest(c, config.DB)
+}
+
+// loadEditableRepairRequest is loadRepairRequest plus the rule that completed requests are admin-only
+func (h *PartHandler) loadEditableRepairRequest(c *gin.Context) (*models.RepairRequest, bool) {
+	request, ok := h.loadRepairRequest(c)
+	if !ok {
+		return nil, false
+	}
+
+	user, _ := currentUser(c)
+	if request.Status == models.StatusCompleted && user.Role != models.RoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change costs of a completed repair request"})
+		return nil, false
+	}
+	return request, true
+}
+
+// loadPart fetches the part named by the :partId parameter within the given request
+func (h *PartHandler) loadPart(c *gin.Context, request *models.RepairRequest) (models.PartUsed, bool) {
+	var part models.PartUsed
+
+	partID, err := strconv.Atoi(c.Param("partId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid part ID"})
+		return part, false
+	}
+
+	if err := config.DB.Where("repair_request_id = ?", request.ID).First(&part, partID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Part not found"})
+		return part, false
+	}
+	return part, true
+}
@@ -1,107 +1,381 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
+	"strings"
+	"time"
 
 	"repair-system/config"
 	"repair-system/models"
 	"repair-system/services"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type RepairRequestHandler struct {
-	telegramService *services.TelegramService
-	settingsService *services.SettingsService
+	settingsService      *services.SettingsService
+	workflowService      *services.WorkflowService
+	queryService         *services.RepairRequestQueryService
+	historyService       *services.HistoryService
+	repairRequestService *services.RepairRequestService
 }
 
 func NewRepairRequestHandler() *RepairRequestHandler {
 	settingsService := services.NewSettingsService()
 	return &RepairRequestHandler{
-		telegramService: services.NewTelegramServiceWithSettings(settingsService),
-		settingsService: settingsService,
+		settingsService:      settingsService,
+		workflowService:      services.NewWorkflowService(),
+		queryService:         services.NewRepairRequestQueryService(),
+		historyService:       services.NewHistoryService(),
+		repairRequestService: services.NewRepairRequestService(settingsService),
 	}
 }
 
 // ListRepairRequests handles GET /api/repair-requests
 func (h *RepairRequestHandler) ListRepairRequests(c *gin.Context) {
-	var requests []models.RepairRequest
-	if err := config.DB.Preload("Category").Preload("Requester").Preload("Technician").Find(&requests).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch repair requests"})
+	filter, err := parseRepairRequestFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, requests)
-}
 
-// GetRepairRequest handles GET /api/repair-requests/:id
-func (h *RepairRequestHandler) GetRepairRequest(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+	user, _ := currentUser(c)
+	page, err := h.queryService.List(config.DB, user, filter)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair request ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch repair requests"})
 		return
 	}
+	c.JSON(http.StatusOK, page)
+}
 
-	var request models.RepairRequest
-	if err := config.DB.Preload("Category").Preload("Requester").Preload("Technician").Preload("Comments").Preload("PartsUsed").First(&request, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+// GetRepairRequest handles GET /api/repair-requests/:id
+func (h *RepairRequestHandler) GetRepairRequest(c *gin.Context) {
+	user, _ := currentUser(c)
+	request, ok := findVisibleRepairRequest(c, config.DB.Preload("Category").Preload("Requester").Preload("Technician").Preload("RejectedBy").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB {
+			return visibleComments(db, user).Order("created_at ASC")
+		}).
+		Preload("Comments.User").Preload("PartsUsed").Preload("Approvals.Approver").Preload("Reopenings.ReopenedBy").Preload("Reopenings.Technician"))
+	if !ok {
 		return
 	}
+	setETag(c, request)
 	c.JSON(http.StatusOK, request)
 }
 
+// CreateRepairRequestRequest is the payload accepted when raising a repair request.
+// Ownership, status, technician and cost are always set by the server.
+type CreateRepairRequestRequest struct {
+	Title       string                `json:"title" binding:"required"`
+	Description string                `json:"description" binding:"required"`
+	Location    string                `json:"location"`
+	CategoryID  uint                  `json:"categoryId" binding:"required"`
+	Priority    models.RepairPriority `json:"priority"`
+	Images      []string              `json:"images" binding:"max=3"`
+}
+
 // CreateRepairRequest handles POST /api/repair-requests
 func (h *RepairRequestHandler) CreateRepairRequest(c *gin.Context) {
-	var request models.RepairRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	var req CreateRepairRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Set default status
-	if request.Status == "" {
-		request.Status = models.StatusPending
+	var category models.Category
+	if err := config.DB.First(&category, req.CategoryID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return
+	}
+
+	// Fall back to the configured default priority
+	if req.Priority == "" {
+		req.Priority = models.RepairPriority(h.settingsService.GetSettingWithDefault(models.SettingDefaultPriority, string(models.PriorityMedium)))
+	}
+	if !req.Priority.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
 	}
 
-	if err := config.DB.Create(&request).Error; err != nil {
+	user, _ := currentUser(c)
+	request := models.RepairRequest{
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		CategoryID:  category.ID,
+		Priority:    req.Priority,
+		Images:      req.Images,
+	}
+	if request.Title == "" || request.Description == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title and description are required"})
+		return
+	}
+
+	if err := h.repairRequestService.Create(&request, user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create repair request"})
 		return
 	}
+	c.JSON(http.StatusCreated, request)
+}
+
+type AppealRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AppealRepairRequest handles POST /api/repair-requests/:id/appeal
+func (h *RepairRequestHandler) AppealRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	if request.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can appeal a rejection"})
+		return
+	}
+
+	var req AppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A reason is required to appeal"})
+		return
+	}
+
+	if err := h.repairRequestService.Appeal(request, user, req.Reason); err != nil {
+		h.respondWithRequesterActionError(c, request, err)
+		return
+	}
+
+	config.DB.Preload("Category").Preload("Requester").Preload("RejectedBy").First(request, request.ID)
+	setETag(c, request)
+	c.JSON(http.StatusOK, request)
+}
+
+// ResubmitRepairRequest handles POST /api/repair-requests/:id/resubmit
+// Fields left out of the body are copied from the rejected request.
+func (h *RepairRequestHandler) ResubmitRepairRequest(c *gin.Context) {
+	original, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
 
-	// Load relationships for response and telegram notification
-	config.DB.Preload("Category").Preload("Requester").First(&request, request.ID)
+	user, _ := currentUser(c)
+	if original.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can resubmit a rejected request"})
+		return
+	}
 
-	// Send Telegram notification for new repair request
-	if h.telegramService.IsEnabled() {
-		go h.telegramService.NotifyNewRepairRequest(&request, &request.Requester)
+	var req struct {
+		Title       string                `json:"title"`
+		Description string                `json:"description"`
+		Location    string                `json:"location"`
+		CategoryID  uint                  `json:"categoryId"`
+		Priority    models.RepairPriority `json:"priority"`
+		Images      []string              `json:"images" binding:"max=3"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Priority != "" && !req.Priority.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+	if req.CategoryID != 0 {
+		if err := config.DB.First(&models.Category{}, req.CategoryID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return
+		}
 	}
 
+	request := models.RepairRequest{
+		Title:       strings.TrimSpace(req.Title),
+		Description: strings.TrimSpace(req.Description),
+		Location:    strings.TrimSpace(req.Location),
+		CategoryID:  req.CategoryID,
+		Priority:    req.Priority,
+		Images:      req.Images,
+	}
+	if err := h.repairRequestService.Resubmit(original, &request, user); err != nil {
+		h.respondWithRequesterActionError(c, original, err)
+		return
+	}
 	c.JSON(http.StatusCreated, request)
 }
 
-// UpdateRepairRequest handles PUT /api/repair-requests/:id
-func (h *RepairRequestHandler) UpdateRepairRequest(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair request ID"})
+func (h *RepairRequestHandler) respondWithRequesterActionError(c *gin.Context, request *models.RepairRequest, err error) {
+	var transitionErr *services.TransitionError
+	switch {
+	case errors.Is(err, services.ErrNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request is not completed", "status": request.Status})
+	case errors.Is(err, services.ErrAlreadySignedOff):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request has already been signed off", "signOff": request.SignOff})
+	case errors.Is(err, services.ErrInvalidRating):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Rating must be between 1 and 5"})
+	case errors.Is(err, services.ErrReopenWindowClosed):
+		c.JSON(http.StatusConflict, gin.H{"error": "The reopen window for this repair request has passed", "completedAt": request.CompletedAt})
+	case errors.Is(err, services.ErrNotRejected):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request is not rejected", "status": request.Status})
+	case errors.Is(err, services.ErrAlreadyAppealed):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request has already been appealed; resubmit it instead"})
+	case errors.Is(err, services.ErrAlreadyResubmitted):
+		c.JSON(http.StatusConflict, gin.H{"error": "Repair request has already been resubmitted"})
+	case errors.As(err, &transitionErr):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+			"missing": transitionErr.Missing,
+		})
+	case errors.Is(err, services.ErrVersionConflict):
+		h.respondWithConflict(c, request.ID)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update repair request"})
+	}
+}
+
+type ReopenRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReopenRepairRequest handles POST /api/repair-requests/:id/reopen
+func (h *RepairRequestHandler) ReopenRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	if request.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can reopen a repair request"})
+		return
+	}
+
+	var req ReopenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A reason is required to reopen"})
+		return
+	}
+
+	if err := h.repairRequestService.Reopen(request, user, req.Reason); err != nil {
+		h.respondWithRequesterActionError(c, request, err)
+		return
+	}
+
+	config.DB.Preload("Category").Preload("Requester").Preload("Technician").
+		Preload("Reopenings.ReopenedBy").Preload("Reopenings.Technician").First(request, request.ID)
+	setETag(c, request)
+	c.JSON(http.StatusOK, request)
+}
+
+type AcceptRequest struct {
+	Rating  int    `json:"rating" binding:"required"`
+	Comment string `json:"comment"`
+}
+
+// AcceptRepairRequest handles POST /api/repair-requests/:id/accept
+func (h *RepairRequestHandler) AcceptRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	if request.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can accept a repair"})
+		return
+	}
+
+	var req AcceptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repairRequestService.Accept(request, user, req.Rating, req.Comment); err != nil {
+		h.respondWithRequesterActionError(c, request, err)
+		return
+	}
+
+	config.DB.Preload("Category").Preload("Requester").Preload("Technician").First(request, request.ID)
+	setETag(c, request)
+	c.JSON(http.StatusOK, request)
+}
+
+type DisputeRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// DisputeRepairRequest handles POST /api/repair-requests/:id/dispute
+func (h *RepairRequestHandler) DisputeRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	if request.RequesterID != user.ID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can dispute a repair"})
+		return
+	}
+
+	var req DisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "A reason is required to dispute"})
 		return
 	}
 
-	var request models.RepairRequest
-	if err := config.DB.Preload("Technician").First(&request, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+	if err := h.repairRequestService.Dispute(request, user, req.Reason); err != nil {
+		h.respondWithRequesterActionError(c, request, err)
 		return
 	}
 
-	// Store old values for notification comparison
-	oldStatus := string(request.Status)
-	oldTechnicianID := request.TechnicianID
+	config.DB.Preload("Category").Preload("Requester").Preload("Technician").First(request, request.ID)
+	setETag(c, request)
+	c.JSON(http.StatusOK, request)
+}
+
+// UpdateRepairRequest handles PUT /api/repair-requests/:id
+// Only non-zero fields are applied; use PATCH to clear a field.
+func (h *RepairRequestHandler) UpdateRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB.Preload("Technician"))
+	if !ok {
+		return
+	}
+	before := *request
 
 	var updateData models.RepairRequest
 	if err := c.ShouldBindJSON(&updateData); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if !h.checkVersion(c, request, updateData.Version) {
+		return
+	}
+	if updateData.Priority != "" && !updateData.Priority.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid priority"})
+		return
+	}
+	if updateData.Status != "" && !updateData.Status.IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
 
 	// Update fields
 	if updateData.Title != "" {
@@ -128,68 +402,177 @@ func (h *RepairRequestHandler) UpdateRepairRequest(c *gin.Context) {
 	if updateData.RejectionReason != "" {
 		request.RejectionReason = updateData.RejectionReason
 	}
-	if updateData.Cost != 0 {
-		request.Cost = updateData.Cost
+	// Cost is computed from parts, labor and other costs; the client value is ignored
+	if updateData.LaborCost < 0 || updateData.OtherCost < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Costs cannot be negative"})
+		return
+	}
+	if updateData.LaborCost != 0 {
+		request.LaborCost = updateData.LaborCost
+	}
+	if updateData.OtherCost != 0 {
+		request.OtherCost = updateData.OtherCost
 	}
 	if updateData.CompletedAt != nil {
 		request.CompletedAt = updateData.CompletedAt
 	}
 
-	if err := config.DB.Save(&request).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update repair request"})
+	h.saveRepairRequest(c, request, &before)
+}
+
+// PatchRepairRequest handles PATCH /api/repair-requests/:id
+// The body is a JSON Merge Patch (RFC 7386): present fields are applied, null clears a field.
+func (h *RepairRequestHandler) PatchRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB.Preload("Technician"))
+	if !ok {
 		return
 	}
+	before := *request
 
-	// Load relationships for response and notifications
-	config.DB.Preload("Category").Preload("Requester").Preload("Technician").First(&request, request.ID)
+	var patch map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Body must be a JSON object"})
+		return
+	}
 
-	// Send Telegram notifications for updates
-	if h.telegramService.IsEnabled() {
-		// Notify status change
-		if string(request.Status) != oldStatus {
-			go h.telegramService.NotifyStatusChange(&request, oldStatus, request.Technician)
+	var bodyVersion uint
+	if raw, ok := patch["version"]; ok {
+		if err := json.Unmarshal(raw, &bodyVersion); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+			return
 		}
+		delete(patch, "version")
+	}
+	if !h.checkVersion(c, request, bodyVersion) {
+		return
+	}
 
-		// Notify assignment change
-		if request.TechnicianID != oldTechnicianID && request.TechnicianID != nil {
-			go h.telegramService.NotifyAssignment(&request, request.Technician)
-		}
+	if err := applyRepairRequestPatch(request, patch); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
 
-		// Notify completion
-		if request.Status == models.StatusCompleted {
-			go h.telegramService.NotifyCompletion(&request, request.Technician)
-		}
+	h.saveRepairRequest(c, request, &before)
+}
 
-		// Notify rejection
-		if request.Status == models.StatusRejected && request.RejectionReason != "" {
-			// Get admin user who made the rejection
-			userValue, exists := c.Get("user")
-			if exists {
-				if admin, ok := userValue.(*models.User); ok {
-					go h.telegramService.NotifyRejection(&request, request.RejectionReason, admin)
-				}
-			}
+// checkVersion rejects stale writes. The client's version comes from If-Match or the body.
+func (h *RepairRequestHandler) checkVersion(c *gin.Context, request *models.RepairRequest, bodyVersion uint) bool {
+	expectedVersion, err := expectedVersion(c, bodyVersion)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	if expectedVersion != 0 && expectedVersion != request.Version {
+		h.respondWithConflict(c, request.ID)
+		return false
+	}
+	return true
+}
+
+// saveRepairRequest persists an edited request through the repair request service and
+// writes the response. before is the request as loaded.
+func (h *RepairRequestHandler) saveRepairRequest(c *gin.Context, request *models.RepairRequest, before *models.RepairRequest) {
+	user, _ := currentUser(c)
+
+	err := h.repairRequestService.Update(request, before, user)
+	var transitionErr *services.TransitionError
+	switch {
+	case errors.Is(err, services.ErrCompletedCostLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can change costs of a completed repair request"})
+		return
+	case errors.As(err, &transitionErr):
+		status := http.StatusConflict
+		if len(transitionErr.Missing) > 0 {
+			status = http.StatusUnprocessableEntity
 		}
+		c.JSON(status, gin.H{
+			"error":   transitionErr.Error(),
+			"from":    transitionErr.From,
+			"to":      transitionErr.To,
+			"allowed": transitionErr.Allowed,
+			"missing": transitionErr.Missing,
+		})
+		return
+	case errors.Is(err, services.ErrVersionConflict):
+		h.respondWithConflict(c, request.ID)
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update repair request"})
+		return
 	}
 
+	// Load relationships for response
+	request.Technician = nil
+	config.DB.Preload("Category").Preload("Requester").Preload("Technician").Preload("RejectedBy").First(request, request.ID)
+
+	setETag(c, request)
 	c.JSON(http.StatusOK, request)
 }
 
-// DeleteRepairRequest handles DELETE /api/repair-requests/:id
-func (h *RepairRequestHandler) DeleteRepairRequest(c *gin.Context) {
-	id, err := strconv.Atoi(c.Param("id"))
+// GetRepairRequestTransitions handles GET /api/repair-requests/:id/transitions
+func (h *RepairRequestHandler) GetRepairRequestTransitions(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	user, _ := currentUser(c)
+	var category models.Category
+	config.DB.Unscoped().First(&category, request.CategoryID)
+	approver := category.ApproverID != nil && *category.ApproverID == user.ID
+	c.JSON(http.StatusOK, gin.H{
+		"status":      request.Status,
+		"transitions": h.workflowService.AllowedTransitions(request.Status, user.Role, approver),
+	})
+}
+
+// respondWithConflict writes a 409 carrying the current representation so the client can merge
+func (h *RepairRequestHandler) respondWithConflict(c *gin.Context, id uint) {
+	var current models.RepairRequest
+	if err := config.DB.Preload("Category").Preload("Requester").Preload("Technician").First(&current, id).Error; err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": services.ErrVersionConflict.Error()})
+		return
+	}
+	setETag(c, &current)
+	c.JSON(http.StatusConflict, gin.H{
+		"error":   services.ErrVersionConflict.Error(),
+		"current": current,
+	})
+}
+
+// GetRepairRequestHistory handles GET /api/repair-requests/:id/history
+func (h *RepairRequestHandler) GetRepairRequestHistory(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
+		return
+	}
+
+	events, err := h.historyService.Events(config.DB, request.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair request ID"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch history"})
 		return
 	}
 
-	var request models.RepairRequest
-	if err := config.DB.First(&request, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Repair request not found"})
+	// Report durations in seconds so clients don't depend on Go's duration format
+	durations := gin.H{}
+	for status, d := range h.historyService.StatusDurations(events, time.Now()) {
+		durations[string(status)] = int64(d.Seconds())
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":    events,
+		"durations": durations,
+	})
+}
+
+// DeleteRepairRequest handles DELETE /api/repair-requests/:id
+func (h *RepairRequestHandler) DeleteRepairRequest(c *gin.Context) {
+	request, ok := findVisibleRepairRequest(c, config.DB)
+	if !ok {
 		return
 	}
 
-	if err := config.DB.Delete(&request).Error; err != nil {
+	if err := config.DB.Delete(request).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete repair request"})
 		return
 	}
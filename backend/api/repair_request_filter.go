@@ -0,0 +1,147 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseRepairRequestFilter reads the listing filters, sort order and page from the query string
+func parseRepairRequestFilter(c *gin.Context) (services.RepairRequestFilter, error) {
+	filter := services.RepairRequestFilter{
+		Sort:       "created",
+		Descending: true,
+		Page:       1,
+		Limit:      services.DefaultPageLimit,
+	}
+
+	for _, value := range splitQueryList(c.Query("status")) {
+		status := models.RepairStatus(value)
+		if !status.IsValid() {
+			return filter, fmt.Errorf("invalid status: %s", value)
+		}
+		filter.Statuses = append(filter.Statuses, status)
+	}
+
+	for _, value := range splitQueryList(c.Query("priority")) {
+		priority := models.RepairPriority(value)
+		if !priority.IsValid() {
+			return filter, fmt.Errorf("invalid priority: %s", value)
+		}
+		filter.Priorities = append(filter.Priorities, priority)
+	}
+
+	var err error
+	if filter.CategoryID, err = parseQueryID(c, "categoryId"); err != nil {
+		return filter, err
+	}
+	if filter.RequesterID, err = parseQueryID(c, "requesterId"); err != nil {
+		return filter, err
+	}
+	if c.Query("technicianId") == "unassigned" {
+		filter.Unassigned = true
+	} else if filter.TechnicianID, err = parseQueryID(c, "technicianId"); err != nil {
+		return filter, err
+	}
+	filter.Location = strings.TrimSpace(c.Query("location"))
+
+	if filter.CreatedFrom, err = parseQueryDate(c, "createdFrom", false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedTo, err = parseQueryDate(c, "createdTo", true); err != nil {
+		return filter, err
+	}
+	if filter.CompletedFrom, err = parseQueryDate(c, "completedFrom", false); err != nil {
+		return filter, err
+	}
+	if filter.CompletedTo, err = parseQueryDate(c, "completedTo", true); err != nil {
+		return filter, err
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		switch sort {
+		case "created", "updated", "priority", "age":
+			filter.Sort = sort
+		default:
+			return filter, fmt.Errorf("invalid sort: %s", sort)
+		}
+	}
+	if order := c.Query("order"); order != "" {
+		switch order {
+		case "asc":
+			filter.Descending = false
+		case "desc":
+			filter.Descending = true
+		default:
+			return filter, fmt.Errorf("invalid order: %s", order)
+		}
+	}
+
+	if value := c.Query("page"); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			return filter, fmt.Errorf("invalid page: %s", value)
+		}
+		filter.Page = page
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > services.MaxPageLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", services.MaxPageLimit)
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// splitQueryList splits a comma separated query value, dropping empty entries
+func splitQueryList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+func parseQueryID(c *gin.Context, key string) (*uint, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	result := uint(id)
+	return &result, nil
+}
+
+// parseQueryDate accepts either a date (2006-01-02) or an RFC3339 timestamp.
+// A plain date used as an upper bound covers the whole day.
+func parseQueryDate(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
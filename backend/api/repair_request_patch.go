@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+)
+
+// applyRepairRequestPatch applies a JSON Merge Patch to the request. Only editable fields
+// are accepted; null clears optional fields and is rejected for required ones.
+func applyRepairRequestPatch(request *models.RepairRequest, patch map[string]json.RawMessage) error {
+	for field, raw := range patch {
+		isNull := string(raw) == "null"
+
+		switch field {
+		case "title", "description":
+			var value string
+			if isNull || json.Unmarshal(raw, &value) != nil || strings.TrimSpace(value) == "" {
+				return fmt.Errorf("%s must be a non-empty string", field)
+			}
+			if field == "title" {
+				request.Title = strings.TrimSpace(value)
+			} else {
+				request.Description = strings.TrimSpace(value)
+			}
+
+		case "location", "rejectionReason":
+			var value string
+			if !isNull && json.Unmarshal(raw, &value) != nil {
+				return fmt.Errorf("%s must be a string or null", field)
+			}
+			if field == "location" {
+				request.Location = strings.TrimSpace(value)
+			} else {
+				request.RejectionReason = strings.TrimSpace(value)
+			}
+
+		case "categoryId":
+			var value uint
+			if isNull || json.Unmarshal(raw, &value) != nil {
+				return fmt.Errorf("categoryId must be a category ID")
+			}
+			var category models.Category
+			if err := config.DB.First(&category, value).Error; err != nil {
+				return fmt.Errorf("category %d not found", value)
+			}
+			request.CategoryID = value
+
+		case "technicianId":
+			if isNull {
+				request.TechnicianID = nil
+				request.Technician = nil
+				continue
+			}
+			var value uint
+			if json.Unmarshal(raw, &value) != nil {
+				return fmt.Errorf("technicianId must be a user ID or null")
+			}
+			var technician models.User
+			if err := config.DB.First(&technician, value).Error; err != nil {
+				return fmt.Errorf("technician %d not found", value)
+			}
+			if technician.Role != models.RoleTechnician && technician.Role != models.RoleAdmin {
+				return fmt.Errorf("user %d cannot be assigned repair requests", value)
+			}
+			request.TechnicianID = &value
+			request.Technician = nil
+
+		case "status":
+			var value models.RepairStatus
+			if isNull || json.Unmarshal(raw, &value) != nil || !value.IsValid() {
+				return fmt.Errorf("status must be a valid repair status")
+			}
+			request.Status = value
+
+		case "priority":
+			var value models.RepairPriority
+			if isNull || json.Unmarshal(raw, &value) != nil || !value.IsValid() {
+				return fmt.Errorf("priority must be one of low, medium, high, urgent")
+			}
+			request.Priority = value
+
+		case "laborCost", "otherCost":
+			var value float64
+			if !isNull && (json.Unmarshal(raw, &value) != nil || value < 0) {
+				return fmt.Errorf("%s must be a non-negative number or null", field)
+			}
+			if field == "laborCost" {
+				request.LaborCost = value
+			} else {
+				request.OtherCost = value
+			}
+
+		case "completedAt":
+			if isNull {
+				request.CompletedAt = nil
+				continue
+			}
+			var value time.Time
+			if json.Unmarshal(raw, &value) != nil {
+				return fmt.Errorf("completedAt must be an RFC 3339 timestamp or null")
+			}
+			request.CompletedAt = &value
+
+		default:
+			return fmt.Errorf("field %s cannot be patched", field)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+)
+
+func TestApplyRepairRequestPatch(t *testing.T) {
+	setupTestDB(t)
+	category := models.Category{Name: "Plumbing"}
+	technician := models.User{Username: "tech", Email: "tech@example.com", Password: "x", Role: models.RoleTechnician}
+	requester := models.User{Username: "req", Email: "req@example.com", Password: "x", Role: models.RoleRequester}
+	for _, record := range []interface{}{&category, &technician, &requester} {
+		if err := config.DB.Create(record).Error; err != nil {
+			t.Fatalf("create %T: %v", record, err)
+		}
+	}
+
+	completedAt := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr string
+		check   func(request *models.RepairRequest) bool
+	}{
+		{"trims title", `{"title":"  Leaking tap  "}`, "", func(r *models.RepairRequest) bool { return r.Title == "Leaking tap" }},
+		{"null title", `{"title":null}`, "title must be a non-empty string", nil},
+		{"blank description", `{"description":"   "}`, "description must be a non-empty string", nil},
+		{"null clears location", `{"location":null}`, "", func(r *models.RepairRequest) bool { return r.Location == "" }},
+		{"location number", `{"location":12}`, "location must be a string or null", nil},
+		{"rejection reason", `{"rejectionReason":"No budget"}`, "", func(r *models.RepairRequest) bool { return r.RejectionReason == "No budget" }},
+		{"category", fmt.Sprintf(`{"categoryId":%d}`, category.ID), "", func(r *models.RepairRequest) bool { return r.CategoryID == category.ID }},
+		{"missing category", `{"categoryId":999}`, "category 999 not found", nil},
+		{"null category", `{"categoryId":null}`, "categoryId must be a category ID", nil},
+		{"assign technician", fmt.Sprintf(`{"technicianId":%d}`, technician.ID), "", func(r *models.RepairRequest) bool {
+			return r.TechnicianID != nil && *r.TechnicianID == technician.ID && r.Technician == nil
+		}},
+		{"null unassigns", `{"technicianId":null}`, "", func(r *models.RepairRequest) bool { return r.TechnicianID == nil && r.Technician == nil }},
+		{"assign requester", fmt.Sprintf(`{"technicianId":%d}`, requester.ID), "cannot be assigned repair requests", nil},
+		{"technician string", `{"technicianId":"2"}`, "technicianId must be a user ID or null", nil},
+		{"status", `{"status":"in_progress"}`, "", func(r *models.RepairRequest) bool { return r.Status == models.StatusInProgress }},
+		{"unknown status", `{"status":"done"}`, "status must be a valid repair status", nil},
+		{"priority", `{"priority":"urgent"}`, "", func(r *models.RepairRequest) bool { return r.Priority == models.PriorityUrgent }},
+		{"null priority", `{"priority":null}`, "priority must be one of", nil},
+		{"costs", `{"laborCost":150.5,"otherCost":null}`, "", func(r *models.RepairRequest) bool { return r.LaborCost == 150.5 && r.OtherCost == 0 }},
+		{"negative cost", `{"otherCost":-1}`, "otherCost must be a non-negative number or null", nil},
+		{"completed at", `{"completedAt":"2024-05-01T09:30:00Z"}`, "", func(r *models.RepairRequest) bool {
+			return r.CompletedAt != nil && r.CompletedAt.Equal(completedAt)
+		}},
+		{"null clears completed at", `{"completedAt":null}`, "", func(r *models.RepairRequest) bool { return r.CompletedAt == nil }},
+		{"bad completed at", `{"completedAt":"yesterday"}`, "completedAt must be an RFC 3339 timestamp or null", nil},
+		{"read-only field", `{"requesterId":1}`, "field requesterId cannot be patched", nil},
+		{"empty patch", `{}`, "", func(r *models.RepairRequest) bool { return r.Title == "Broken tap" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldTechnicianID := technician.ID
+			oldCompletedAt := completedAt.Add(-time.Hour)
+			request := models.RepairRequest{
+				Title:        "Broken tap",
+				Description:  "Kitchen tap drips",
+				Location:     "Building A",
+				Status:       models.StatusPending,
+				Priority:     models.PriorityMedium,
+				LaborCost:    10,
+				OtherCost:    20,
+				TechnicianID: &oldTechnicianID,
+				Technician:   &technician,
+				CompletedAt:  &oldCompletedAt,
+			}
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("bad test patch: %v", err)
+			}
+
+			err := applyRepairRequestPatch(&request, patch)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("applyRepairRequestPatch = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyRepairRequestPatch: %v", err)
+			}
+			if !tt.check(&request) {
+				t.Errorf("unexpected request after patch: %+v", request)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler() *ReportHandler {
+	return &ReportHandler{
+		reportService: services.NewReportService(),
+	}
+}
+
+// TechnicianRatings handles GET /api/reports/ratings/technicians
+func (h *ReportHandler) TechnicianRatings(c *gin.Context) {
+	period, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	technicians, overall, err := h.reportService.TechnicianRatings(period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build technician ratings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"technicians": technicians, "overall": overall})
+}
+
+// CategoryRatings handles GET /api/reports/ratings/categories
+func (h *ReportHandler) CategoryRatings(c *gin.Context) {
+	period, ok := parseReportPeriod(c)
+	if !ok {
+		return
+	}
+
+	categories, overall, err := h.reportService.CategoryRatings(period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build category ratings"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": categories, "overall": overall})
+}
+
+// parseReportPeriod reads the from and to completion dates, answering 400 when either is invalid
+func parseReportPeriod(c *gin.Context) (services.ReportPeriod, bool) {
+	var period services.ReportPeriod
+	var err error
+	if period.From, err = parseQueryDate(c, "from", false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return period, false
+	}
+	if period.To, err = parseQueryDate(c, "to", true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return period, false
+	}
+	return period, true
+}
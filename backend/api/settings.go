@@ -1,7 +1,12 @@
 package api
 
 import (
+	"errors"
+	"html/template"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"repair-system/models"
@@ -11,23 +16,48 @@ import (
 )
 
 type SettingsHandler struct {
-	settingsService *services.SettingsService
+	settingsService    *services.SettingsService
+	assignmentService  *services.AssignmentService
+	maintenanceService *services.MaintenanceService
+	emailService       *services.EmailService
+	dispatcher         *services.NotificationDispatcher
 }
 
 func NewSettingsHandler() *SettingsHandler {
+	settingsService := services.NewSettingsService()
 	return &SettingsHandler{
-		settingsService: services.NewSettingsService(),
+		settingsService:    settingsService,
+		assignmentService:  services.NewAssignmentService(settingsService),
+		maintenanceService: services.NewMaintenanceService(settingsService),
+		emailService:       services.NewEmailService(settingsService),
+		dispatcher:         services.NewNotificationDispatcher(settingsService),
 	}
 }
 
 type TelegramSettings struct {
-	Enabled              bool   `json:"enabled"`
-	BotToken             string `json:"botToken"`
-	ChatID               string `json:"chatId"`
-	NotifyOnNewRequest   bool   `json:"notifyOnNewRequest"`
-	NotifyOnStatusChange bool   `json:"notifyOnStatusChange"`
-	NotifyOnAssignment   bool   `json:"notifyOnAssignment"`
-	NotifyOnCompletion   bool   `json:"notifyOnCompletion"`
+	Enabled              bool    `json:"enabled"`
+	BotToken             string  `json:"botToken"`
+	ChatID               string  `json:"chatId"`
+	NotifyOnNewRequest   bool    `json:"notifyOnNewRequest"`
+	NotifyOnStatusChange bool    `json:"notifyOnStatusChange"`
+	NotifyOnAssignment   bool    `json:"notifyOnAssignment"`
+	NotifyOnCompletion   bool    `json:"notifyOnCompletion"`
+	NotifyOnRejection    *bool   `json:"notifyOnRejection"` // Left unchanged when omitted
+	BotUsername          *string `json:"botUsername"`       // Looked up from Telegram when empty; left unchanged when omitted
+	UpdateMode           string  `json:"updateMode"`        // off, polling or webhook
+	WebhookSecret        string  `json:"webhookSecret"`     // Secret token given to setWebhook
+	APIBaseURL           *string `json:"apiBaseUrl"`        // Empty uses api.telegram.org; left unchanged when omitted
+	ProxyURL             *string `json:"proxyUrl"`          // Empty uses HTTPS_PROXY from the environment; left unchanged when omitted
+}
+
+type EmailSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+	TLSMode  string `json:"tlsMode"` // none, starttls or tls
 }
 
 type SystemSettings struct {
@@ -35,13 +65,20 @@ type SystemSettings struct {
 	SiteDescription       string `json:"siteDescription"`
 	AdminEmail            string `json:"adminEmail"`
 	AutoAssignTechnicians bool   `json:"autoAssignTechnicians"`
+	AutoAssignStrategy    string `json:"autoAssignStrategy"`
 	RequireApproval       bool   `json:"requireApproval"`
 	DefaultPriority       string `json:"defaultPriority"`
 	MaintenanceMode       bool   `json:"maintenanceMode"`
+	MaintenanceMessage    string `json:"maintenanceMessage"`
+	MaintenanceRetryAfter int    `json:"maintenanceRetryAfter"`
+	NotificationLocale    string `json:"notificationLocale"` // th or en
+	ReopenWindowDays      *int   `json:"reopenWindowDays"`   // 0 turns reopening off; left unchanged when omitted
+	SignOffDays           *int   `json:"signOffDays"`        // 0 never auto-closes; left unchanged when omitted
 }
 
 type Settings struct {
 	Telegram TelegramSettings `json:"telegram"`
+	Email    *EmailSettings   `json:"email,omitempty"` // Left unchanged when omitted
 	System   SystemSettings   `json:"system"`
 }
 
@@ -50,6 +87,14 @@ func (h *SettingsHandler) GetSettings(c *gin.Context) {
 	// Initialize default settings if needed
 	h.settingsService.InitializeDefaultSettings()
 
+	maintenance := h.maintenanceService.Status()
+	smtpConfig := h.emailService.Config()
+	reopenWindowDays, _ := strconv.Atoi(h.settingsService.GetSettingWithDefault(models.SettingReopenWindowDays, "7"))
+	signOffDays, _ := strconv.Atoi(h.settingsService.GetSettingWithDefault(models.SettingSignOffDays, "7"))
+	notifyOnRejection := h.settingsService.GetBoolSetting(models.SettingTelegramNotifyRejection)
+	botUsername := h.settingsService.GetSettingWithDefault(models.SettingTelegramBotUsername, "")
+	apiBaseURL := h.settingsService.GetSettingWithDefault(models.SettingTelegramAPIBaseURL, "")
+	proxyURL := h.settingsService.GetSettingWithDefault(models.SettingTelegramProxyURL, "")
 	settings := Settings{
 		Telegram: TelegramSettings{
 			Enabled:              h.settingsService.GetBoolSetting(models.SettingTelegramEnabled),
@@ -59,15 +104,36 @@ func (h *SettingsHandler) GetSettings(c *gin.Context) {
 			NotifyOnStatusChange: h.settingsService.GetBoolSetting(models.SettingTelegramNotifyStatusChange),
 			NotifyOnAssignment:   h.settingsService.GetBoolSetting(models.SettingTelegramNotifyAssignment),
 			NotifyOnCompletion:   h.settingsService.GetBoolSetting(models.SettingTelegramNotifyCompletion),
+			NotifyOnRejection:    &notifyOnRejection,
+			BotUsername:          &botUsername,
+			UpdateMode:           h.settingsService.GetSettingWithDefault(models.SettingTelegramUpdateMode, services.TelegramUpdateModeOff),
+			APIBaseURL:           &apiBaseURL,
+			ProxyURL:             &proxyURL,
+			WebhookSecret:        "***hidden***",
+		},
+		Email: &EmailSettings{
+			Enabled:  h.settingsService.GetBoolSetting(models.SettingEmailEnabled),
+			Host:     smtpConfig.Host,
+			Port:     smtpConfig.Port,
+			Username: smtpConfig.Username,
+			Password: "***hidden***", // Don't expose the actual password for security
+			From:     smtpConfig.From,
+			TLSMode:  smtpConfig.TLSMode,
 		},
 		System: SystemSettings{
 			SiteName:              h.settingsService.GetSettingWithDefault(models.SettingSiteName, "Repair System"),
 			SiteDescription:       h.settingsService.GetSettingWithDefault(models.SettingSiteDescription, "ระบบแจ้งซ่อมออนไลน์"),
 			AdminEmail:            h.settingsService.GetSettingWithDefault(models.SettingAdminEmail, "admin@example.com"),
 			AutoAssignTechnicians: h.settingsService.GetBoolSetting(models.SettingAutoAssignTechnicians),
+			AutoAssignStrategy:    h.settingsService.GetSettingWithDefault(models.SettingAutoAssignStrategy, "least_workload"),
 			RequireApproval:       h.settingsService.GetBoolSetting(models.SettingRequireApproval),
 			DefaultPriority:       h.settingsService.GetSettingWithDefault(models.SettingDefaultPriority, "medium"),
-			MaintenanceMode:       h.settingsService.GetBoolSetting(models.SettingMaintenanceMode),
+			MaintenanceMode:       maintenance.Enabled,
+			MaintenanceMessage:    maintenance.Message,
+			MaintenanceRetryAfter: maintenance.RetryAfter,
+			NotificationLocale:    h.settingsService.GetSettingWithDefault(models.SettingNotificationLocale, services.LocaleThai),
+			ReopenWindowDays:      &reopenWindowDays,
+			SignOffDays:           &signOffDays,
 		},
 	}
 
@@ -121,6 +187,77 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
+	if settings.Telegram.NotifyOnRejection != nil {
+		if err := h.settingsService.SetBoolSetting(models.SettingTelegramNotifyRejection, *settings.Telegram.NotifyOnRejection); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification settings"})
+			return
+		}
+	}
+
+	if settings.Telegram.BotUsername != nil {
+		if err := h.settingsService.SetSetting(models.SettingTelegramBotUsername, strings.TrimPrefix(*settings.Telegram.BotUsername, "@")); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update telegram bot username"})
+			return
+		}
+	}
+
+	if settings.Telegram.UpdateMode != "" {
+		switch settings.Telegram.UpdateMode {
+		case services.TelegramUpdateModeOff, services.TelegramUpdateModePolling, services.TelegramUpdateModeWebhook:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown telegram update mode"})
+			return
+		}
+		if err := h.settingsService.SetSetting(models.SettingTelegramUpdateMode, settings.Telegram.UpdateMode); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update telegram update mode"})
+			return
+		}
+	}
+
+	// Only update webhook secret if it's not the hidden placeholder
+	if settings.Telegram.WebhookSecret != "" && settings.Telegram.WebhookSecret != "***hidden***" {
+		if err := h.settingsService.SetSetting(models.SettingTelegramWebhookSecret, settings.Telegram.WebhookSecret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update telegram webhook secret"})
+			return
+		}
+	}
+
+	if settings.Telegram.APIBaseURL != nil {
+		apiBaseURL := strings.TrimRight(strings.TrimSpace(*settings.Telegram.APIBaseURL), "/")
+		if apiBaseURL != "" {
+			if parsed, err := url.Parse(apiBaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Telegram API base URL must be an http or https URL"})
+				return
+			}
+		}
+		if err := h.settingsService.SetSetting(models.SettingTelegramAPIBaseURL, apiBaseURL); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update telegram API base URL"})
+			return
+		}
+	}
+
+	if settings.Telegram.ProxyURL != nil {
+		proxyURL := strings.TrimSpace(*settings.Telegram.ProxyURL)
+		if proxyURL != "" {
+			if _, err := services.ParseTelegramProxyURL(proxyURL); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := h.settingsService.SetSetting(models.SettingTelegramProxyURL, proxyURL); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update telegram proxy URL"})
+			return
+		}
+	}
+
+	// Update Email settings
+	if settings.Email != nil {
+		if err := h.updateEmailSettings(settings.Email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	// Update System settings
 	if err := h.settingsService.SetSetting(models.SettingSiteName, settings.System.SiteName); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update site name"})
@@ -142,6 +279,17 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
+	if settings.System.AutoAssignStrategy != "" {
+		if !h.assignmentService.HasStrategy(settings.System.AutoAssignStrategy) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown auto assign strategy"})
+			return
+		}
+		if err := h.settingsService.SetSetting(models.SettingAutoAssignStrategy, settings.System.AutoAssignStrategy); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update auto assign strategy"})
+			return
+		}
+	}
+
 	if err := h.settingsService.SetBoolSetting(models.SettingRequireApproval, settings.System.RequireApproval); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update require approval setting"})
 		return
@@ -157,6 +305,56 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 		return
 	}
 
+	if settings.System.MaintenanceMessage != "" {
+		if err := h.settingsService.SetSetting(models.SettingMaintenanceMessage, settings.System.MaintenanceMessage); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update maintenance message"})
+			return
+		}
+	}
+
+	if settings.System.MaintenanceRetryAfter > 0 {
+		if err := h.settingsService.SetSetting(models.SettingMaintenanceRetryAfter, strconv.Itoa(settings.System.MaintenanceRetryAfter)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update maintenance retry after"})
+			return
+		}
+	}
+
+	if settings.System.NotificationLocale != "" {
+		if !services.IsNotificationLocale(settings.System.NotificationLocale) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown notification locale"})
+			return
+		}
+		if err := h.settingsService.SetSetting(models.SettingNotificationLocale, settings.System.NotificationLocale); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification locale"})
+			return
+		}
+	}
+
+	if settings.System.ReopenWindowDays != nil {
+		if *settings.System.ReopenWindowDays < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Reopen window cannot be negative"})
+			return
+		}
+		if err := h.settingsService.SetSetting(models.SettingReopenWindowDays, strconv.Itoa(*settings.System.ReopenWindowDays)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update reopen window"})
+			return
+		}
+	}
+
+	if settings.System.SignOffDays != nil {
+		if *settings.System.SignOffDays < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Sign-off period cannot be negative"})
+			return
+		}
+		if err := h.settingsService.SetSetting(models.SettingSignOffDays, strconv.Itoa(*settings.System.SignOffDays)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update sign-off period"})
+			return
+		}
+	}
+
+	// Make the new maintenance settings take effect immediately
+	h.maintenanceService.Invalidate()
+
 	c.JSON(http.StatusOK, gin.H{"message": "Settings updated successfully"})
 }
 
@@ -197,12 +395,10 @@ func (h *SettingsHandler) TestTelegram(c *gin.Context) {
 		}
 	}
 
-	// Create a temporary telegram service for testing
-	testService := &services.TelegramService{
-		BotToken: botToken,
-		ChatID:   chatID,
-		Enabled:  true,
-	}
+	// Create a temporary telegram service for testing, keeping the configured API URL and proxy
+	testService := services.NewTelegramServiceWithSettings(h.settingsService).ForChat(chatID)
+	testService.BotToken = botToken
+	testService.Enabled = true
 
 	// Send test message
 	message := `🔧 <b>ระบบแจ้งซ่อม - ทดสอบการแจ้งเตือน</b>
@@ -218,3 +414,163 @@ func (h *SettingsHandler) TestTelegram(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Telegram test completed successfully"})
 }
+
+// updateEmailSettings validates and stores the SMTP settings
+func (h *SettingsHandler) updateEmailSettings(email *EmailSettings) error {
+	if email.TLSMode == "" {
+		email.TLSMode = services.SMTPTLSStartTLS
+	}
+	if email.Enabled {
+		config := h.smtpConfig(email)
+		if err := config.Validate(); err != nil {
+			return err
+		}
+	}
+
+	values := map[string]string{
+		models.SettingSMTPHost:     email.Host,
+		models.SettingSMTPPort:     strconv.Itoa(email.Port),
+		models.SettingSMTPUsername: email.Username,
+		models.SettingSMTPFrom:     email.From,
+		models.SettingSMTPTLSMode:  email.TLSMode,
+	}
+	// Only update the password if it's not the hidden placeholder
+	if email.Password != "***hidden***" {
+		values[models.SettingSMTPPassword] = email.Password
+	}
+	for key, value := range values {
+		if err := h.settingsService.SetSetting(key, value); err != nil {
+			return errors.New("failed to update email settings")
+		}
+	}
+	return h.settingsService.SetBoolSetting(models.SettingEmailEnabled, email.Enabled)
+}
+
+// smtpConfig builds an SMTP configuration from submitted settings, taking the stored
+// password when the placeholder is sent back
+func (h *SettingsHandler) smtpConfig(email *EmailSettings) services.SMTPConfig {
+	password := email.Password
+	if password == "***hidden***" {
+		password = h.emailService.Config().Password
+	}
+	return services.SMTPConfig{
+		Host:     email.Host,
+		Port:     email.Port,
+		Username: email.Username,
+		Password: password,
+		From:     email.From,
+		TLSMode:  email.TLSMode,
+	}
+}
+
+// TestEmail handles POST /api/settings/test-email
+func (h *SettingsHandler) TestEmail(c *gin.Context) {
+	var testData struct {
+		EmailSettings
+		To string `json:"to"`
+	}
+
+	if err := c.ShouldBindJSON(&testData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Use the stored configuration unless a host is given
+	config := h.emailService.Config()
+	if testData.Host != "" {
+		if testData.TLSMode == "" {
+			testData.TLSMode = services.SMTPTLSStartTLS
+		}
+		config = h.smtpConfig(&testData.EmailSettings)
+	}
+
+	// Send to the admin address unless a recipient is given
+	to := testData.To
+	if to == "" {
+		to = h.settingsService.GetSettingWithDefault(models.SettingAdminEmail, "")
+		if to == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Recipient is required"})
+			return
+		}
+	}
+
+	siteName := h.settingsService.GetSettingWithDefault(models.SettingSiteName, "Repair System")
+	now := time.Now().Format("02/01/2006 15:04")
+	subject := "[" + siteName + "] ทดสอบการแจ้งเตือนทางอีเมล"
+	text := "การเชื่อมต่อ SMTP สำเร็จ!\nเวลา: " + now + "\nระบบ: " + siteName + "\n"
+	html := "<p>✅ การเชื่อมต่อ SMTP สำเร็จ!</p><p><b>เวลา:</b> " + now + "<br><b>ระบบ:</b> " + template.HTMLEscapeString(siteName) + "</p>"
+
+	if err := h.emailService.Send(config, []string{to}, subject, text, html); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to send test email: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email test completed successfully"})
+}
+
+type NotificationChannelStatus struct {
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"` // Whether the channel itself is configured and switched on
+}
+
+type NotificationMatrix struct {
+	Events   []string                    `json:"events"`
+	Channels []NotificationChannelStatus `json:"channels"`
+	Rules    []services.NotificationRule `json:"rules"`
+}
+
+// GetNotificationMatrix handles GET /api/settings/notifications
+func (h *SettingsHandler) GetNotificationMatrix(c *gin.Context) {
+	c.JSON(http.StatusOK, h.notificationMatrix())
+}
+
+// UpdateNotificationMatrix handles PUT /api/settings/notifications
+// Only the rules in the body change; the rest of the matrix is left as it is.
+func (h *SettingsHandler) UpdateNotificationMatrix(c *gin.Context) {
+	var req struct {
+		Rules []services.NotificationRule `json:"rules" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Reject the whole update if any rule is unknown, before anything is saved
+	known := map[services.NotificationRule]bool{}
+	for _, rule := range h.dispatcher.Rules() {
+		rule.Enabled = false
+		known[rule] = true
+	}
+	for _, rule := range req.Rules {
+		cell := rule
+		cell.Enabled = false
+		if !known[cell] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown notification rule", "rule": rule})
+			return
+		}
+	}
+
+	for _, rule := range req.Rules {
+		if err := h.dispatcher.SetRule(rule); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification settings"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, h.notificationMatrix())
+}
+
+func (h *SettingsHandler) notificationMatrix() NotificationMatrix {
+	matrix := NotificationMatrix{
+		Events:   services.NotificationEvents,
+		Channels: []NotificationChannelStatus{},
+		Rules:    h.dispatcher.Rules(),
+	}
+	for _, notifier := range h.dispatcher.Notifiers() {
+		matrix.Channels = append(matrix.Channels, NotificationChannelStatus{
+			Channel: notifier.Channel(),
+			Enabled: notifier.IsEnabled(),
+		})
+	}
+	return matrix
+}
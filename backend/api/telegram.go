@@ -0,0 +1,49 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TelegramHandler struct {
+	telegramBotService *services.TelegramBotService
+}
+
+func NewTelegramHandler() *TelegramHandler {
+	return &TelegramHandler{
+		telegramBotService: services.NewTelegramBotService(services.NewSettingsService()),
+	}
+}
+
+// ReceiveUpdate handles POST /api/telegram/webhook
+func (h *TelegramHandler) ReceiveUpdate(c *gin.Context) {
+	if h.telegramBotService.UpdateMode() != services.TelegramUpdateModeWebhook {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Telegram webhook is disabled"})
+		return
+	}
+
+	// Telegram echoes the secret given to setWebhook; without one anyone could post updates
+	secret := h.telegramBotService.WebhookSecret()
+	token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid secret token"})
+		return
+	}
+
+	var update services.TelegramUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Always acknowledge so Telegram doesn't redeliver an update we can't handle
+	if err := h.telegramBotService.HandleUpdate(update); err != nil {
+		log.Printf("Warning: Failed to handle telegram update %d: %v", update.UpdateID, err)
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
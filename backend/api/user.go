@@ -20,7 +20,7 @@ func NewUserHandler() *UserHandler {
 // ListUsers handles GET /api/users
 func (h *UserHandler) ListUsers(c *gin.Context) {
 	var users []models.User
-	if err := config.DB.Find(&users).Error; err != nil {
+	if err := config.DB.Preload("Skills").Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
@@ -36,7 +36,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	}
 
 	var user models.User
-	if err := config.DB.First(&user, id).Error; err != nil {
+	if err := config.DB.Preload("Skills").First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
@@ -45,7 +45,8 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 // CreateUser handles POST /api/users
 func (h *UserHandler) CreateUser(c *gin.Context) {
-	var user models.User
+	// Defaults for fields the body leaves out; an explicit false is kept
+	user := models.User{Available: true, TelegramDMs: true}
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -156,3 +157,74 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// UpdateAvailability handles PUT /api/users/:id/availability
+func (h *UserHandler) UpdateAvailability(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req struct {
+		Available *bool `json:"available" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := config.DB.Model(&user).Update("available", *req.Available).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update availability"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateSkills handles PUT /api/users/:id/skills
+func (h *UserHandler) UpdateSkills(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var user models.User
+	if err := config.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	var req struct {
+		CategoryIDs []uint `json:"categoryIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	skills := []models.Category{}
+	if len(req.CategoryIDs) > 0 {
+		if err := config.DB.Find(&skills, req.CategoryIDs).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories"})
+			return
+		}
+		if len(skills) != len(req.CategoryIDs) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return
+		}
+	}
+
+	if err := config.DB.Model(&user).Association("Skills").Replace(skills); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update skills"})
+		return
+	}
+	user.Skills = skills
+	c.JSON(http.StatusOK, user)
+}
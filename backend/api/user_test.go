@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"repair-system/config"
+	"repair-system/models"
+)
+
+func TestCreateUserKeepsFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *bool
+		want  bool
+	}{
+		{"omitted", nil, true},
+		{"true", boolPtr(true), true},
+		{"false", boolPtr(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			h := NewUserHandler()
+
+			body := map[string]interface{}{
+				"username": "somchai",
+				"email":    "somchai@example.com",
+				"role":     models.RoleTechnician,
+			}
+			if tt.value != nil {
+				body["available"] = *tt.value
+				body["telegramDms"] = *tt.value
+			}
+			w := serveJSON(t, h.CreateUser, http.MethodPost, "/api/users", body)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var created models.User
+			if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			var stored models.User
+			if err := config.DB.First(&stored, created.ID).Error; err != nil {
+				t.Fatalf("load user: %v", err)
+			}
+			if created.Available != tt.want || stored.Available != tt.want {
+				t.Errorf("available = %v in response, %v stored, want %v", created.Available, stored.Available, tt.want)
+			}
+			if created.TelegramDMs != tt.want || stored.TelegramDMs != tt.want {
+				t.Errorf("telegramDms = %v in response, %v stored, want %v", created.TelegramDMs, stored.TelegramDMs, tt.want)
+			}
+		})
+	}
+}
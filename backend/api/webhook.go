@@ -0,0 +1,230 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"repair-system/config"
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: services.NewWebhookService(),
+	}
+}
+
+type WebhookRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	URL          string   `json:"url" binding:"required"`
+	Events       []string `json:"events" binding:"required,min=1"`
+	Active       *bool    `json:"active"`
+	Secret       string   `json:"secret"`       // Generated when empty on create
+	RotateSecret bool     `json:"rotateSecret"` // Generates a new secret on update
+}
+
+// WebhookWithSecret is returned when a secret is created, the only time it is shown
+type WebhookWithSecret struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// ListWebhooks handles GET /api/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	webhooks := []models.Webhook{}
+	if err := config.DB.Order("id ASC").Find(&webhooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": webhooks, "events": services.WebhookEvents})
+}
+
+// CreateWebhook handles POST /api/webhooks
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook := models.Webhook{Active: true}
+	if !h.apply(c, &webhook, &req) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = h.webhookService.GenerateSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+	}
+	if err := h.webhookService.SetSecret(&webhook, secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt secret"})
+		return
+	}
+
+	if err := config.DB.Create(&webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	c.JSON(http.StatusCreated, WebhookWithSecret{Webhook: webhook, Secret: secret})
+}
+
+// UpdateWebhook handles PUT /api/webhooks/:id
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.apply(c, webhook, &req) {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" && req.RotateSecret {
+		var err error
+		if secret, err = h.webhookService.GenerateSecret(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+			return
+		}
+	}
+	if secret != "" {
+		if err := h.webhookService.SetSecret(webhook, secret); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encrypt secret"})
+			return
+		}
+	}
+
+	if err := config.DB.Save(webhook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	if secret != "" {
+		c.JSON(http.StatusOK, WebhookWithSecret{Webhook: *webhook, Secret: secret})
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook handles DELETE /api/webhooks/:id
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	// Deliveries still waiting to be sent would otherwise go to the deleted endpoint
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(webhook).Error; err != nil {
+			return err
+		}
+		return h.webhookService.CancelPending(tx, webhook.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListDeliveries handles GET /api/webhooks/:id/deliveries
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	query := config.DB.Where("webhook_id = ?", webhook.ID).Order("id DESC").Limit(services.MaxPageLimit)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	if err := query.Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// RedeliverWebhook handles POST /api/webhooks/:id/deliveries/:deliveryId/redeliver
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	webhook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.Atoi(c.Param("deliveryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := config.DB.Where("webhook_id = ?", webhook.ID).First(&delivery, deliveryID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+
+	if err := h.webhookService.Redeliver(&delivery); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to queue redelivery"})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// apply validates the request and copies it onto the webhook
+func (h *WebhookHandler) apply(c *gin.Context, webhook *models.Webhook, req *WebhookRequest) bool {
+	req.URL = strings.TrimSpace(req.URL)
+	if err := services.ValidateWebhookURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+	for _, event := range req.Events {
+		if !services.IsValidWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown event " + event, "events": services.WebhookEvents})
+			return false
+		}
+	}
+
+	webhook.Name = strings.TrimSpace(req.Name)
+	webhook.URL = req.URL
+	webhook.Events = req.Events
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	return true
+}
+
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+		return nil, false
+	}
+
+	var webhook models.Webhook
+	if err := config.DB.First(&webhook, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return nil, false
+	}
+	return &webhook, true
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"repair-system/config"
+	"repair-system/models"
+)
+
+func TestCreateWebhookKeepsActive(t *testing.T) {
+	tests := []struct {
+		name   string
+		active *bool
+		want   bool
+	}{
+		{"omitted", nil, true},
+		{"true", boolPtr(true), true},
+		{"false", boolPtr(false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDB(t)
+			h := NewWebhookHandler()
+
+			body := map[string]interface{}{
+				"name":   "Ops",
+				"url":    "https://example.com/hook",
+				"events": []string{models.EventRequestCreated},
+			}
+			if tt.active != nil {
+				body["active"] = *tt.active
+			}
+			w := serveJSON(t, h.CreateWebhook, http.MethodPost, "/api/webhooks", body)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var created WebhookWithSecret
+			if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+
+			var stored models.Webhook
+			if err := config.DB.First(&stored, created.ID).Error; err != nil {
+				t.Fatalf("load webhook: %v", err)
+			}
+			if created.Active != tt.want || stored.Active != tt.want {
+				t.Errorf("active = %v in response, %v stored, want %v", created.Active, stored.Active, tt.want)
+			}
+		})
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
@@ -31,6 +31,14 @@ func InitDB() {
 		&models.RepairRequest{},
 		&models.Comment{},
 		&models.PartUsed{},
+		&models.Approval{},
+		&models.Reopening{},
+		&models.RepairRequestEvent{},
+		&models.OutboxMessage{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.TelegramLinkCode{},
+		&models.NotificationTemplate{},
 		&models.Setting{},
 	)
 	if err != nil {
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"repair-system/api"
 	"repair-system/config"
@@ -34,6 +35,16 @@ func main() {
 		log.Printf("Warning: Failed to initialize default settings: %v", err)
 	}
 
+	// Deliver queued notifications in the background
+	services.NewOutboxService(settingsService).Start(5 * time.Second)
+	services.NewWebhookService().Start(5 * time.Second)
+
+	// Close completed jobs the requester never signed off
+	services.NewRepairRequestService(settingsService).StartAutoClose(time.Hour)
+
+	// Receive Telegram bot updates by long polling when configured to
+	services.NewTelegramBotService(settingsService).StartPolling()
+
 	// Initialize router
 	r := gin.Default()
 
@@ -41,34 +52,76 @@ func main() {
 	config := cors.DefaultConfig()
 	config.AllowOrigins = []string{"http://localhost:3000"}
 	config.AllowCredentials = true
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", "If-Match"}
+	config.ExposeHeaders = []string{"ETag", "Retry-After"}
 	r.Use(cors.New(config))
 
 	// Initialize handlers
 	authHandler := api.NewAuthHandler()
 	repairRequestHandler := api.NewRepairRequestHandler()
+	commentHandler := api.NewCommentHandler()
+	partHandler := api.NewPartHandler()
+	approvalHandler := api.NewApprovalHandler()
+	assignmentHandler := api.NewAssignmentHandler()
 	categoryHandler := api.NewCategoryHandler()
 	userHandler := api.NewUserHandler()
 	settingsHandler := api.NewSettingsHandler()
+	notificationHandler := api.NewNotificationHandler()
+	webhookHandler := api.NewWebhookHandler()
+	notificationTemplateHandler := api.NewNotificationTemplateHandler()
+	meHandler := api.NewMeHandler()
+	telegramHandler := api.NewTelegramHandler()
 	uploadHandler := api.NewUploadHandler()
+	reportHandler := api.NewReportHandler()
 
-	// Public routes
-	r.POST("/api/auth/register", authHandler.Register)
+	// Public routes (login stays open during maintenance so admins can sign in)
+	r.POST("/api/auth/register", middleware.MaintenanceMode(), authHandler.Register)
 	r.POST("/api/auth/login", authHandler.Login)
 
+	// Telegram bot updates (authenticated by the webhook secret token)
+	r.POST("/api/telegram/webhook", telegramHandler.ReceiveUpdate)
+
 	// Protected routes
 	protected := r.Group("/api")
 	protected.Use(middleware.AuthMiddleware())
+	protected.Use(middleware.MaintenanceMode())
 	{
 		// Repair Request routes (all authenticated users can view, create)
 		protected.GET("/repair-requests", repairRequestHandler.ListRepairRequests)
 		protected.GET("/repair-requests/:id", repairRequestHandler.GetRepairRequest)
+		protected.GET("/repair-requests/:id/transitions", repairRequestHandler.GetRepairRequestTransitions)
+		protected.GET("/repair-requests/:id/history", repairRequestHandler.GetRepairRequestHistory)
 		protected.POST("/repair-requests", repairRequestHandler.CreateRepairRequest)
+		protected.POST("/repair-requests/:id/appeal", repairRequestHandler.AppealRepairRequest)
+		protected.POST("/repair-requests/:id/resubmit", repairRequestHandler.ResubmitRepairRequest)
+		protected.POST("/repair-requests/:id/reopen", repairRequestHandler.ReopenRepairRequest)
+		protected.POST("/repair-requests/:id/accept", repairRequestHandler.AcceptRepairRequest)
+		protected.POST("/repair-requests/:id/dispute", repairRequestHandler.DisputeRepairRequest)
+
+		// Comment routes (authors edit their own, admins can moderate)
+		protected.GET("/repair-requests/:id/comments", commentHandler.ListComments)
+		protected.POST("/repair-requests/:id/comments", commentHandler.CreateComment)
+		protected.PUT("/repair-requests/:id/comments/:commentId", commentHandler.UpdateComment)
+		protected.DELETE("/repair-requests/:id/comments/:commentId", commentHandler.DeleteComment)
+
+		// Approval routes (admins and designated category approvers)
+		protected.GET("/approvals", approvalHandler.ListPendingApprovals)
+		protected.POST("/repair-requests/:id/approval", approvalHandler.DecideApproval)
+
+		// Parts and cost routes (all authenticated users can view)
+		protected.GET("/repair-requests/:id/parts", partHandler.ListParts)
+		protected.GET("/repair-requests/:id/cost", partHandler.GetCost)
 
 		// Category routes (all authenticated users can view)
 		protected.GET("/categories", categoryHandler.ListCategories)
 		protected.GET("/categories/:id", categoryHandler.GetCategory)
 
+		// Personal notification preferences
+		protected.GET("/me/notifications", meHandler.GetNotificationPreferences)
+		protected.PUT("/me/notifications", meHandler.UpdateNotificationPreferences)
+		protected.POST("/me/telegram/link", meHandler.CreateTelegramLink)
+		protected.DELETE("/me/telegram", meHandler.UnlinkTelegram)
+
 		// Upload routes (all authenticated users can upload)
 		protected.POST("/upload/image", uploadHandler.UploadImage)
 	}
@@ -92,27 +145,68 @@ func main() {
 		adminRoutes.POST("/users", userHandler.CreateUser)
 		adminRoutes.PUT("/users/:id", userHandler.UpdateUser)
 		adminRoutes.DELETE("/users/:id", userHandler.DeleteUser)
+		adminRoutes.PUT("/users/:id/availability", userHandler.UpdateAvailability)
+		adminRoutes.PUT("/users/:id/skills", userHandler.UpdateSkills)
+
+		// Technician assignment (admin only)
+		adminRoutes.GET("/repair-requests/:id/assignment/preview", assignmentHandler.PreviewAssignment)
+		adminRoutes.POST("/repair-requests/:id/assign", assignmentHandler.AssignTechnician)
 
 		// Settings management (admin only)
 		adminRoutes.GET("/settings", settingsHandler.GetSettings)
 		adminRoutes.PUT("/settings", settingsHandler.UpdateSettings)
 		adminRoutes.POST("/settings/test-telegram", settingsHandler.TestTelegram)
+		adminRoutes.POST("/settings/test-email", settingsHandler.TestEmail)
+		adminRoutes.GET("/settings/notifications", settingsHandler.GetNotificationMatrix)
+		adminRoutes.PUT("/settings/notifications", settingsHandler.UpdateNotificationMatrix)
+
+		// Notification delivery (admin only)
+		adminRoutes.GET("/notifications/outbox", notificationHandler.ListOutbox)
+		adminRoutes.POST("/notifications/outbox/:id/replay", notificationHandler.ReplayOutbox)
+
+		// Outbound webhooks (admin only)
+		adminRoutes.GET("/webhooks", webhookHandler.ListWebhooks)
+		adminRoutes.POST("/webhooks", webhookHandler.CreateWebhook)
+		adminRoutes.PUT("/webhooks/:id", webhookHandler.UpdateWebhook)
+		adminRoutes.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+		adminRoutes.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+		adminRoutes.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+		// Reports (admin only)
+		adminRoutes.GET("/reports/ratings/technicians", reportHandler.TechnicianRatings)
+		adminRoutes.GET("/reports/ratings/categories", reportHandler.CategoryRatings)
+
+		// Notification templates (admin only)
+		adminRoutes.GET("/notification-templates", notificationTemplateHandler.ListNotificationTemplates)
+		adminRoutes.POST("/notification-templates/preview", notificationTemplateHandler.PreviewNotificationTemplate)
+		adminRoutes.PUT("/notification-templates/:channel/:event/:locale", notificationTemplateHandler.UpdateNotificationTemplate)
+		adminRoutes.DELETE("/notification-templates/:channel/:event/:locale", notificationTemplateHandler.ResetNotificationTemplate)
 	}
 
 	// Technician and Admin routes
 	techRoutes := r.Group("/api")
 	techRoutes.Use(middleware.AuthMiddleware())
 	techRoutes.Use(middleware.RequireAdminOrTechnician())
+	techRoutes.Use(middleware.MaintenanceMode())
 	{
 		// Repair Request management (technician/admin only)
 		techRoutes.PUT("/repair-requests/:id", repairRequestHandler.UpdateRepairRequest)
+		techRoutes.PATCH("/repair-requests/:id", repairRequestHandler.PatchRepairRequest)
 		techRoutes.DELETE("/repair-requests/:id", repairRequestHandler.DeleteRepairRequest)
+
+		// Parts and cost management (completed requests are admin-only)
+		techRoutes.POST("/repair-requests/:id/parts", partHandler.CreatePart)
+		techRoutes.PUT("/repair-requests/:id/parts/:partId", partHandler.UpdatePart)
+		techRoutes.DELETE("/repair-requests/:id/parts/:partId", partHandler.DeletePart)
+		techRoutes.PUT("/repair-requests/:id/cost", partHandler.UpdateCost)
 	}
 
 	// Health check endpoint
+	maintenanceService := services.NewMaintenanceService(settingsService)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
-			"status": "ok",
+			"status":      "ok",
+			"maintenance": maintenanceService.Status(),
 		})
 	})
 
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"repair-system/models"
+	"repair-system/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceMode rejects requests with 503 while maintenance mode is on.
+// Admins are let through; it must run after AuthMiddleware to recognise them.
+func MaintenanceMode() gin.HandlerFunc {
+	maintenanceService := services.NewMaintenanceService(services.NewSettingsService())
+
+	return func(c *gin.Context) {
+		status := maintenanceService.Status()
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		if role, exists := c.Get("userRole"); exists && role == models.RoleAdmin {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(status.RetryAfter))
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":       status.Message,
+			"maintenance": true,
+			"retryAfter":  status.RetryAfter,
+		})
+		c.Abort()
+	}
+}
@@ -13,6 +13,8 @@ type Category struct {
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 	Name        string         `gorm:"uniqueIndex;not null" json:"name"`
 	Description string         `json:"description"`
+	ApproverID  *uint          `json:"approverId"` // Optional user who may approve requests in this category besides admins
+	Approver    *User          `json:"approver,omitempty"`
 }
 
 // TableName specifies the table name for the Category model
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Notification event types, shared by every delivery channel
+const (
+	EventRequestCreated       = "request.created"
+	EventRequestStatusChanged = "request.status_changed"
+	EventRequestAssigned      = "request.assigned"
+	EventRequestCompleted     = "request.completed"
+	EventRequestReopened      = "request.reopened"
+	EventRequestSignedOff     = "request.signed_off"
+	EventRequestRejected      = "request.rejected"
+	EventRequestApproval      = "request.approval_decided"
+	EventCommentAdded         = "comment.added"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxSent      OutboxStatus = "sent"
+	OutboxDead      OutboxStatus = "dead"      // Gave up after too many attempts
+	OutboxCancelled OutboxStatus = "cancelled" // What it was about was deleted first
+)
+
+// OutboxMessage is a notification waiting to be delivered over one channel. It is written
+// in the same transaction as the change that caused it and drained by a background worker.
+type OutboxMessage struct {
+	ID            uint         `gorm:"primarykey" json:"ID"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	UpdatedAt     time.Time    `json:"updatedAt"`
+	Event         string       `gorm:"type:varchar(50);not null" json:"event"`
+	Channel       string       `gorm:"type:varchar(30);not null;default:'telegram'" json:"channel"`
+	Payload       string       `gorm:"type:text;not null" json:"payload"` // JSON encoded NotificationPayload
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due" json:"status"`
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time    `gorm:"index:idx_outbox_due" json:"nextAttemptAt"`
+	LastError     string       `gorm:"type:text" json:"lastError"`
+	SentAt        *time.Time   `json:"sentAt"`
+}
+
+// TableName specifies the table name for the OutboxMessage model
+func (OutboxMessage) TableName() string {
+	return "notification_outbox"
+}
+
+// NotificationPayload identifies what a notification is about; the worker reloads the
+// records when delivering so the payload stays small
+type NotificationPayload struct {
+	RepairRequestID uint   `json:"repairRequestId"`
+	ActorID         *uint  `json:"actorId,omitempty"`
+	OldStatus       string `json:"oldStatus,omitempty"`
+	CommentID       uint   `json:"commentId,omitempty"`
+	ApprovalID      uint   `json:"approvalId,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// NotificationTemplate replaces the built-in message for one channel, event and locale.
+// Telegram templates only use Body; email templates use Subject, Body (plain text) and HTML.
+type NotificationTemplate struct {
+	ID        uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Channel   string    `gorm:"uniqueIndex:idx_notification_template;not null" json:"channel"`
+	Event     string    `gorm:"uniqueIndex:idx_notification_template;not null" json:"event"`
+	Locale    string    `gorm:"uniqueIndex:idx_notification_template;not null" json:"locale"`
+	Subject   string    `gorm:"type:text" json:"subject"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	HTML      string    `gorm:"type:text" json:"html"`
+}
+
+// TableName specifies the table name for the NotificationTemplate model
+func (NotificationTemplate) TableName() string {
+	return "notification_templates"
+}
@@ -10,11 +10,12 @@ import (
 type RepairStatus string
 
 const (
-	StatusPending     RepairStatus = "pending"
-	StatusInProgress  RepairStatus = "in_progress"
-	StatusWaitingPart RepairStatus = "waiting_part"
-	StatusCompleted   RepairStatus = "completed"
-	StatusRejected    RepairStatus = "rejected"
+	StatusAwaitingApproval RepairStatus = "awaiting_approval"
+	StatusPending          RepairStatus = "pending"
+	StatusInProgress       RepairStatus = "in_progress"
+	StatusWaitingPart      RepairStatus = "waiting_part"
+	StatusCompleted        RepairStatus = "completed"
+	StatusRejected         RepairStatus = "rejected"
 )
 
 type RepairPriority string
@@ -26,28 +27,74 @@ const (
 	PriorityUrgent RepairPriority = "urgent"
 )
 
+// SignOffStatus is the requester's answer to a completed job
+type SignOffStatus string
+
+const (
+	SignOffAccepted   SignOffStatus = "accepted"
+	SignOffDisputed   SignOffStatus = "disputed"
+	SignOffAutoClosed SignOffStatus = "auto_closed" // The requester did not answer within the sign-off period
+)
+
+// IsValid reports whether the status is one of the known repair statuses
+func (s RepairStatus) IsValid() bool {
+	switch s {
+	case StatusAwaitingApproval, StatusPending, StatusInProgress, StatusWaitingPart, StatusCompleted, StatusRejected:
+		return true
+	}
+	return false
+}
+
+// IsValid reports whether the priority is one of the known repair priorities
+func (p RepairPriority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityUrgent:
+		return true
+	}
+	return false
+}
+
 type RepairRequest struct {
-	ID              uint           `gorm:"primarykey" json:"ID"`
-	CreatedAt       time.Time      `json:"createdAt"`
-	UpdatedAt       time.Time      `json:"updatedAt"`
-	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
-	Title           string         `gorm:"not null" json:"title"`
-	Description     string         `gorm:"type:text;not null" json:"description"`
-	Location        string         `json:"location"`
-	CategoryID      uint           `json:"categoryId"`
-	Category        Category       `json:"category"`
-	RequesterID     uint           `json:"requesterId"`
-	Requester       User           `json:"requester"`
-	TechnicianID    *uint          `json:"technicianId"`
-	Technician      *User          `json:"technician"`
-	Status          RepairStatus   `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
-	Priority        RepairPriority `gorm:"type:varchar(20);default:'medium'" json:"priority"`
-	Images          pq.StringArray `gorm:"type:text[]" json:"images"`
-	CompletedAt     *time.Time     `json:"completedAt"`
-	RejectionReason string         `json:"rejectionReason"`
-	Comments        []Comment      `json:"comments"`
-	Cost            float64        `json:"cost"`
-	PartsUsed       []PartUsed     `json:"partsUsed"`
+	ID                uint           `gorm:"primarykey" json:"ID"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+	Title             string         `gorm:"not null" json:"title"`
+	Description       string         `gorm:"type:text;not null" json:"description"`
+	Location          string         `json:"location"`
+	CategoryID        uint           `json:"categoryId"`
+	Category          Category       `json:"category"`
+	RequesterID       uint           `json:"requesterId"`
+	Requester         User           `json:"requester"`
+	TechnicianID      *uint          `json:"technicianId"`
+	Technician        *User          `json:"technician"`
+	Status            RepairStatus   `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Priority          RepairPriority `gorm:"type:varchar(20);default:'medium'" json:"priority"`
+	Images            pq.StringArray `gorm:"type:text[]" json:"images"`
+	CompletedAt       *time.Time     `json:"completedAt"`
+	RejectionReason   string         `json:"rejectionReason"`
+	RejectedByID      *uint          `json:"rejectedById"`
+	RejectedBy        *User          `json:"rejectedBy,omitempty"`
+	RejectedAt        *time.Time     `json:"rejectedAt"`
+	AppealReason      string         `gorm:"type:text" json:"appealReason"` // Why the requester asked for the rejection to be reconsidered
+	AppealedAt        *time.Time     `json:"appealedAt"`
+	ResubmittedFromID *uint          `gorm:"index" json:"resubmittedFromId"` // The rejected request this one was resubmitted from
+	ReopenCount       int            `gorm:"not null;default:0" json:"reopenCount"`
+	ReopenReason      string         `gorm:"type:text" json:"reopenReason"` // Why the requester reopened it most recently
+	ReopenedAt        *time.Time     `json:"reopenedAt"`
+	Reopenings        []Reopening    `json:"reopenings"`
+	SignOff           SignOffStatus  `gorm:"type:varchar(20);index" json:"signOff"` // Empty until the requester accepts or disputes the completed job
+	SignedOffAt       *time.Time     `json:"signedOffAt"`
+	Rating            *int           `json:"rating"` // 1 to 5, given when the requester accepts
+	RatingComment     string         `gorm:"type:text" json:"ratingComment"`
+	DisputeReason     string         `gorm:"type:text" json:"disputeReason"`
+	Comments          []Comment      `json:"comments"`
+	Cost              float64        `json:"cost"` // Computed server-side: parts subtotal + labor + other
+	LaborCost         float64        `json:"laborCost"`
+	OtherCost         float64        `json:"otherCost"`
+	PartsUsed         []PartUsed     `json:"partsUsed"`
+	Approvals         []Approval     `json:"approvals"`
+	Version           uint           `gorm:"not null;default:1" json:"version"` // Incremented on every change for optimistic locking
 }
 
 // TableName specifies the table name for the RepairRequest model
@@ -64,6 +111,7 @@ type Comment struct {
 	UserID          uint           `json:"userId"`
 	User            User           `json:"user"`
 	Content         string         `gorm:"type:text;not null" json:"content"`
+	IsInternal      bool           `gorm:"default:false" json:"isInternal"` // Internal notes are hidden from the requester
 }
 
 type PartUsed struct {
@@ -76,3 +124,40 @@ type PartUsed struct {
 	Quantity        int            `json:"quantity"`
 	UnitPrice       float64        `json:"unitPrice"`
 }
+
+// Approval records an approve or reject decision on a request awaiting approval
+type Approval struct {
+	ID              uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt       time.Time `json:"createdAt"`
+	RepairRequestID uint      `gorm:"index;not null" json:"repairRequestId"`
+	ApproverID      uint      `gorm:"not null" json:"approverId"`
+	Approver        User      `json:"approver"`
+	Approved        bool      `json:"approved"`
+	Note            string    `gorm:"type:text" json:"note"`
+}
+
+// Reopening records a completion the requester later reopened, so the earlier
+// completion survives once the request is completed again
+type Reopening struct {
+	ID              uint       `gorm:"primarykey" json:"ID"`
+	CreatedAt       time.Time  `json:"createdAt"` // When the request was reopened
+	RepairRequestID uint       `gorm:"index;not null" json:"repairRequestId"`
+	ReopenedByID    uint       `gorm:"not null" json:"reopenedById"`
+	ReopenedBy      User       `json:"reopenedBy"`
+	TechnicianID    *uint      `json:"technicianId"` // Who completed the job
+	Technician      *User      `json:"technician"`
+	CompletedAt     *time.Time `json:"completedAt"`
+	Reason          string     `gorm:"type:text" json:"reason"`
+}
+
+// RepairRequestEvent is an append-only history entry recording a single field change
+type RepairRequestEvent struct {
+	ID              uint      `gorm:"primarykey" json:"ID"`
+	CreatedAt       time.Time `gorm:"index" json:"createdAt"`
+	RepairRequestID uint      `gorm:"index;not null" json:"repairRequestId"`
+	ActorID         *uint     `json:"actorId"` // Nil for changes made by the system
+	Actor           *User     `json:"actor"`
+	Field           string    `gorm:"type:varchar(50);not null" json:"field"`
+	OldValue        string    `json:"oldValue"`
+	NewValue        string    `json:"newValue"`
+}
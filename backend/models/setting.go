@@ -23,13 +23,35 @@ const (
 	SettingTelegramNotifyStatusChange = "telegram_notify_status_change"
 	SettingTelegramNotifyAssignment   = "telegram_notify_assignment"
 	SettingTelegramNotifyCompletion   = "telegram_notify_completion"
+	SettingTelegramNotifyRejection    = "telegram_notify_rejection"
+	SettingTelegramBotUsername        = "telegram_bot_username"   // Used to build t.me deep links
+	SettingTelegramUpdateMode         = "telegram_update_mode"    // off, polling or webhook
+	SettingTelegramWebhookSecret      = "telegram_webhook_secret" // Expected X-Telegram-Bot-Api-Secret-Token
+	SettingTelegramAPIBaseURL         = "telegram_api_base_url"   // Local Bot API server or test stub
+	SettingTelegramProxyURL           = "telegram_proxy_url"      // Empty uses HTTPS_PROXY from the environment
+
+	// Email settings
+	SettingEmailEnabled = "email_enabled"
+	SettingSMTPHost     = "smtp_host"
+	SettingSMTPPort     = "smtp_port"
+	SettingSMTPUsername = "smtp_username"
+	SettingSMTPPassword = "smtp_password"
+	SettingSMTPFrom     = "smtp_from"
+	SettingSMTPTLSMode  = "smtp_tls_mode" // none, starttls or tls
 
 	// System settings
 	SettingSiteName              = "site_name"
 	SettingSiteDescription       = "site_description"
 	SettingAdminEmail            = "admin_email"
 	SettingAutoAssignTechnicians = "auto_assign_technicians"
+	SettingAutoAssignStrategy    = "auto_assign_strategy"
+	SettingAutoAssignLastUserID  = "auto_assign_last_user_id" // Round-robin cursor
 	SettingRequireApproval       = "require_approval"
 	SettingDefaultPriority       = "default_priority"
 	SettingMaintenanceMode       = "maintenance_mode"
+	SettingMaintenanceMessage    = "maintenance_message"
+	SettingMaintenanceRetryAfter = "maintenance_retry_after" // Seconds
+	SettingNotificationLocale    = "notification_locale"     // th or en
+	SettingReopenWindowDays      = "reopen_window_days"      // 0 turns reopening off
+	SettingSignOffDays           = "sign_off_days"           // Days before an unconfirmed job closes itself; 0 never
 )
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TelegramLinkCode is a one-time code that binds a Telegram chat to a user when it is
+// sent to the bot through a t.me deep link
+type TelegramLinkCode struct {
+	ID        uint       `gorm:"primarykey" json:"ID"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UserID    uint       `gorm:"index;not null" json:"userId"`
+	Code      string     `gorm:"uniqueIndex;not null" json:"code"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt"`
+}
+
+// TableName specifies the table name for the TelegramLinkCode model
+func (TelegramLinkCode) TableName() string {
+	return "telegram_link_codes"
+}
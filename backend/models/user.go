@@ -14,6 +14,8 @@ const (
 	RoleRequester  UserRole = "requester"
 )
 
+// Booleans have no column default: GORM would write it in place of false on create,
+// so constructors set them explicitly
 type User struct {
 	ID          uint           `gorm:"primarykey" json:"ID"`
 	CreatedAt   time.Time      `json:"createdAt"`
@@ -25,8 +27,11 @@ type User struct {
 	FullName    string         `json:"fullName"`
 	Role        UserRole       `gorm:"type:varchar(20);not null" json:"role"`
 	PhoneNumber string         `json:"phoneNumber"`
-	TelegramID  string         `json:"telegramId"`
+	TelegramID  string         `json:"telegramId"`                                      // Private chat ID for direct messages
+	TelegramDMs bool           `gorm:"column:telegram_dms;not null" json:"telegramDms"` // Users can opt out of direct messages
 	LastLogin   time.Time      `json:"lastLogin"`
+	Available   bool           `gorm:"not null" json:"available"`                 // Technicians marked unavailable are skipped by auto-assignment
+	Skills      []Category     `gorm:"many2many:technician_skills" json:"skills"` // Categories a technician can handle
 }
 
 // TableName specifies the table name for the User model
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Webhook is an external endpoint that receives signed JSON payloads for the events
+// it subscribes to
+type Webhook struct {
+	ID        uint           `gorm:"primarykey" json:"ID"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Name      string         `gorm:"not null" json:"name"`
+	URL       string         `gorm:"not null" json:"url"`
+	Secret    string         `gorm:"type:text;not null" json:"-"` // Encrypted signing secret
+	Events    pq.StringArray `gorm:"type:text[]" json:"events"`
+	Active    bool           `gorm:"not null" json:"active"` // No column default: GORM would write it in place of false
+}
+
+// TableName specifies the table name for the Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes reports whether the webhook wants the event
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"    // Gave up after too many attempts
+	DeliveryCancelled WebhookDeliveryStatus = "cancelled" // The webhook was deleted or turned off first
+)
+
+// WebhookDelivery is one event sent to one webhook, kept as a delivery log
+type WebhookDelivery struct {
+	ID             uint                  `gorm:"primarykey" json:"ID"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+	WebhookID      uint                  `gorm:"index;not null" json:"webhookId"`
+	Event          string                `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string                `gorm:"type:text;not null" json:"payload"` // Request body exactly as signed
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_webhook_delivery_due" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"index:idx_webhook_delivery_due" json:"nextAttemptAt"`
+	ResponseStatus int                   `json:"responseStatus"`
+	ResponseBody   string                `gorm:"type:text" json:"responseBody"` // Truncated
+	LastError      string                `gorm:"type:text" json:"lastError"`
+	DeliveredAt    *time.Time            `json:"deliveredAt"`
+}
+
+// TableName specifies the table name for the WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoTechnicianAvailable is returned when no technician can take a request
+var ErrNoTechnicianAvailable = errors.New("no available technician")
+
+// AssignmentStrategy picks a technician for a request out of the available candidates.
+// Candidates are never empty and are ordered by ID.
+type AssignmentStrategy interface {
+	Name() string
+	Pick(request *models.RepairRequest, candidates []models.User) (*models.User, error)
+}
+
+type AssignmentService struct {
+	settingsService *SettingsService
+	historyService  *HistoryService
+	outboxService   *OutboxService
+	strategies      map[string]AssignmentStrategy
+}
+
+func NewAssignmentService(settingsService *SettingsService) *AssignmentService {
+	s := &AssignmentService{
+		settingsService: settingsService,
+		historyService:  NewHistoryService(),
+		outboxService:   NewOutboxService(settingsService),
+		strategies:      map[string]AssignmentStrategy{},
+	}
+	s.Register(&RoundRobinStrategy{settingsService: settingsService})
+	s.Register(&LeastWorkloadStrategy{})
+	s.Register(&SkillMatchStrategy{fallback: &LeastWorkloadStrategy{}})
+	return s
+}
+
+// Register adds or replaces a strategy under its name
+func (s *AssignmentService) Register(strategy AssignmentStrategy) {
+	s.strategies[strategy.Name()] = strategy
+}
+
+// Strategies returns the names of all registered strategies
+func (s *AssignmentService) Strategies() []string {
+	names := make([]string, 0, len(s.strategies))
+	for name := range s.strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasStrategy reports whether a strategy with the given name is registered
+func (s *AssignmentService) HasStrategy(name string) bool {
+	_, ok := s.strategies[name]
+	return ok
+}
+
+// IsEnabled reports whether requests should be assigned automatically
+func (s *AssignmentService) IsEnabled() bool {
+	return s.settingsService.GetBoolSetting(models.SettingAutoAssignTechnicians)
+}
+
+// Preview returns the technician the named strategy (or the configured one when empty)
+// would pick, without assigning anything
+func (s *AssignmentService) Preview(request *models.RepairRequest, strategyName string) (*models.User, string, error) {
+	if strategyName == "" {
+		strategyName = s.settingsService.GetSettingWithDefault(models.SettingAutoAssignStrategy, "least_workload")
+	}
+	strategy, ok := s.strategies[strategyName]
+	if !ok {
+		return nil, strategyName, errors.New("unknown assignment strategy: " + strategyName)
+	}
+
+	candidates, err := s.availableTechnicians()
+	if err != nil {
+		return nil, strategyName, err
+	}
+	if len(candidates) == 0 {
+		return nil, strategyName, ErrNoTechnicianAvailable
+	}
+
+	technician, err := strategy.Pick(request, candidates)
+	return technician, strategyName, err
+}
+
+// AutoAssign assigns a technician to an unassigned pending request when auto-assignment
+// is enabled. It returns nil without error when nothing was assigned.
+func (s *AssignmentService) AutoAssign(request *models.RepairRequest, actorID *uint) (*models.User, error) {
+	if !s.IsEnabled() || request.TechnicianID != nil || request.Status != models.StatusPending {
+		return nil, nil
+	}
+
+	technician, _, err := s.Preview(request, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Assign(request, technician, actorID); err != nil {
+		return nil, err
+	}
+	return technician, nil
+}
+
+// Assign stores the technician on the request and records the change in its history.
+// actorID is nil when the system made the assignment.
+func (s *AssignmentService) Assign(request *models.RepairRequest, technician *models.User, actorID *uint) error {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := UpdateRepairRequestColumns(tx, request, map[string]interface{}{"technician_id": technician.ID}); err != nil {
+			return err
+		}
+		err := s.historyService.RecordChange(tx, request.ID, actorID, HistoryFieldTechnician,
+			s.historyService.FormatID(request.TechnicianID), s.historyService.FormatID(&technician.ID))
+		if err != nil {
+			return err
+		}
+		return s.outboxService.Enqueue(tx, models.EventRequestAssigned, models.NotificationPayload{RepairRequestID: request.ID, ActorID: actorID})
+	})
+	if err != nil {
+		return err
+	}
+	request.TechnicianID = &technician.ID
+	request.Technician = technician
+
+	// Move the round-robin cursor so manual and automatic assignments share one rotation
+	return s.settingsService.SetSetting(models.SettingAutoAssignLastUserID, strconv.FormatUint(uint64(technician.ID), 10))
+}
+
+// availableTechnicians returns technicians that are currently accepting work, with their skills
+func (s *AssignmentService) availableTechnicians() ([]models.User, error) {
+	var technicians []models.User
+	err := config.DB.Preload("Skills").
+		Where("role = ? AND available = ?", models.RoleTechnician, true).
+		Order("id ASC").
+		Find(&technicians).Error
+	return technicians, err
+}
+
+// RoundRobinStrategy hands requests to technicians in turn
+type RoundRobinStrategy struct {
+	settingsService *SettingsService
+}
+
+func (s *RoundRobinStrategy) Name() string {
+	return "round_robin"
+}
+
+func (s *RoundRobinStrategy) Pick(request *models.RepairRequest, candidates []models.User) (*models.User, error) {
+	lastID, _ := strconv.ParseUint(s.settingsService.GetSettingWithDefault(models.SettingAutoAssignLastUserID, "0"), 10, 64)
+	for i := range candidates {
+		if uint64(candidates[i].ID) > lastID {
+			return &candidates[i], nil
+		}
+	}
+	return &candidates[0], nil
+}
+
+// LeastWorkloadStrategy picks the technician with the fewest open requests
+type LeastWorkloadStrategy struct{}
+
+func (s *LeastWorkloadStrategy) Name() string {
+	return "least_workload"
+}
+
+func (s *LeastWorkloadStrategy) Pick(request *models.RepairRequest, candidates []models.User) (*models.User, error) {
+	type workload struct {
+		TechnicianID uint
+		Open         int64
+	}
+	var rows []workload
+	err := config.DB.Model(&models.RepairRequest{}).
+		Select("technician_id, COUNT(*) AS open").
+		Where("technician_id IS NOT NULL AND status IN ?", []models.RepairStatus{models.StatusPending, models.StatusInProgress, models.StatusWaitingPart}).
+		Group("technician_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	open := map[uint]int64{}
+	for _, row := range rows {
+		open[row.TechnicianID] = row.Open
+	}
+
+	best := &candidates[0]
+	for i := range candidates {
+		if open[candidates[i].ID] < open[best.ID] {
+			best = &candidates[i]
+		}
+	}
+	return best, nil
+}
+
+// SkillMatchStrategy restricts the choice to technicians skilled in the request's category,
+// then defers to the fallback strategy. Without a skilled technician all candidates are used.
+type SkillMatchStrategy struct {
+	fallback AssignmentStrategy
+}
+
+func (s *SkillMatchStrategy) Name() string {
+	return "skill_match"
+}
+
+func (s *SkillMatchStrategy) Pick(request *models.RepairRequest, candidates []models.User) (*models.User, error) {
+	var skilled []models.User
+	for _, technician := range candidates {
+		for _, skill := range technician.Skills {
+			if skill.ID == request.CategoryID {
+				skilled = append(skilled, technician)
+				break
+			}
+		}
+	}
+
+	if len(skilled) == 0 {
+		return s.fallback.Pick(request, candidates)
+	}
+	return s.fallback.Pick(request, skilled)
+}
@@ -34,11 +34,13 @@ func (s *AuthService) Register(username, password, email, fullName string, role
 
 	// Create user
 	user := &models.User{
-		Username: username,
-		Password: string(hashedPassword),
-		Email:    email,
-		FullName: fullName,
-		Role:     role,
+		Username:    username,
+		Password:    string(hashedPassword),
+		Email:       email,
+		FullName:    fullName,
+		Role:        role,
+		Available:   true,
+		TelegramDMs: true,
 	}
 
 	if err := config.DB.Create(user).Error; err != nil {
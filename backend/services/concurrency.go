@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+
+	"repair-system/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict is returned when a repair request changed since the caller read it
+var ErrVersionConflict = errors.New("repair request was modified by someone else")
+
+// SaveRepairRequest writes every column of request, but only if the stored version still
+// equals expectedVersion. On success the version is incremented.
+func SaveRepairRequest(tx *gorm.DB, request *models.RepairRequest, expectedVersion uint) error {
+	request.Version = expectedVersion + 1
+	result := tx.Model(&models.RepairRequest{}).
+		Where("id = ? AND version = ?", request.ID, expectedVersion).
+		Select("*").
+		Omit(clause.Associations, "id", "created_at").
+		Updates(request)
+	if result.Error != nil {
+		request.Version = expectedVersion
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		request.Version = expectedVersion
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// UpdateRepairRequestColumns updates the given columns and bumps the version so clients
+// holding the old representation get a conflict on their next write
+func UpdateRepairRequestColumns(tx *gorm.DB, request *models.RepairRequest, columns map[string]interface{}) error {
+	columns["version"] = gorm.Expr("version + 1")
+	if err := tx.Model(&models.RepairRequest{}).Where("id = ?", request.ID).Updates(columns).Error; err != nil {
+		return err
+	}
+	request.Version++
+	return nil
+}
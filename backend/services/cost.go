@@ -0,0 +1,61 @@
+package services
+
+import (
+	"math"
+
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// CostBreakdown is the itemised cost of a repair request
+type CostBreakdown struct {
+	Parts         []models.PartUsed `json:"parts"`
+	PartsSubtotal float64           `json:"partsSubtotal"`
+	LaborCost     float64           `json:"laborCost"`
+	OtherCost     float64           `json:"otherCost"`
+	Total         float64           `json:"total"`
+}
+
+type CostService struct{}
+
+func NewCostService() *CostService {
+	return &CostService{}
+}
+
+// Breakdown computes the cost of a request from its parts, labor and other costs
+func (s *CostService) Breakdown(db *gorm.DB, request *models.RepairRequest) (*CostBreakdown, error) {
+	var parts []models.PartUsed
+	if err := db.Where("repair_request_id = ?", request.ID).Order("id ASC").Find(&parts).Error; err != nil {
+		return nil, err
+	}
+
+	breakdown := &CostBreakdown{
+		Parts:     parts,
+		LaborCost: request.LaborCost,
+		OtherCost: request.OtherCost,
+	}
+	for _, part := range parts {
+		breakdown.PartsSubtotal += float64(part.Quantity) * part.UnitPrice
+	}
+	breakdown.PartsSubtotal = s.round(breakdown.PartsSubtotal)
+	breakdown.Total = s.round(breakdown.PartsSubtotal + breakdown.LaborCost + breakdown.OtherCost)
+
+	return breakdown, nil
+}
+
+// Recalculate recomputes request.Cost and persists it using the given connection or transaction
+func (s *CostService) Recalculate(db *gorm.DB, request *models.RepairRequest) error {
+	breakdown, err := s.Breakdown(db, request)
+	if err != nil {
+		return err
+	}
+
+	request.Cost = breakdown.Total
+	return db.Model(&models.RepairRequest{}).Where("id = ?", request.ID).Update("cost", request.Cost).Error
+}
+
+// round rounds an amount to two decimal places
+func (s *CostService) round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
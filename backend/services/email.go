@@ -0,0 +1,309 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"repair-system/models"
+)
+
+// SMTP TLS modes
+const (
+	SMTPTLSNone     = "none"     // Plain connection, for local relays and test sinks
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection, usually port 587
+	SMTPTLSImplicit = "tls"      // TLS from the first byte, usually port 465
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig holds the connection details of the outgoing mail server
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLSMode  string
+}
+
+// Validate checks that the configuration is complete enough to send mail
+func (c SMTPConfig) Validate() error {
+	if c.Host == "" {
+		return errors.New("SMTP host is required")
+	}
+	if c.Port < 1 || c.Port > 65535 {
+		return errors.New("SMTP port must be between 1 and 65535")
+	}
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid sender address: %v", err)
+	}
+	switch c.TLSMode {
+	case SMTPTLSNone, SMTPTLSStartTLS, SMTPTLSImplicit:
+		return nil
+	default:
+		return fmt.Errorf("unknown TLS mode %s", c.TLSMode)
+	}
+}
+
+// EmailService sends notifications by email. It implements Notifier.
+type EmailService struct {
+	settingsService *SettingsService
+	templates       *NotificationTemplateService
+}
+
+func NewEmailService(settingsService *SettingsService) *EmailService {
+	return &EmailService{
+		settingsService: settingsService,
+		templates:       NewNotificationTemplateService(settingsService),
+	}
+}
+
+// Channel implements Notifier
+func (s *EmailService) Channel() string {
+	return "email"
+}
+
+// IsEnabled reports whether email is switched on and configured
+func (s *EmailService) IsEnabled() bool {
+	if !s.settingsService.GetBoolSetting(models.SettingEmailEnabled) {
+		return false
+	}
+	return s.Config().Validate() == nil
+}
+
+// Config loads the SMTP configuration from settings
+func (s *EmailService) Config() SMTPConfig {
+	port, _ := strconv.Atoi(s.settingsService.GetSettingWithDefault(models.SettingSMTPPort, "587"))
+	return SMTPConfig{
+		Host:     s.settingsService.GetSettingWithDefault(models.SettingSMTPHost, ""),
+		Port:     port,
+		Username: s.settingsService.GetSettingWithDefault(models.SettingSMTPUsername, ""),
+		Password: s.settingsService.GetSettingWithDefault(models.SettingSMTPPassword, ""),
+		From:     s.settingsService.GetSettingWithDefault(models.SettingSMTPFrom, ""),
+		TLSMode:  s.settingsService.GetSettingWithDefault(models.SettingSMTPTLSMode, SMTPTLSStartTLS),
+	}
+}
+
+// Audiences implements Notifier
+func (s *EmailService) Audiences(event string) []string {
+	switch event {
+	case models.EventRequestCreated:
+		return []string{NotificationAudienceAdmin}
+	case models.EventRequestAssigned, models.EventRequestReopened, models.EventRequestSignedOff:
+		return []string{NotificationAudienceTechnician}
+	case models.EventCommentAdded:
+		return []string{NotificationAudienceRequester, NotificationAudienceTechnician}
+	default:
+		return []string{NotificationAudienceRequester}
+	}
+}
+
+func (s *EmailService) NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error {
+	adminEmail := s.settingsService.GetSettingWithDefault(models.SettingAdminEmail, "")
+	return s.sendTemplate(models.EventRequestCreated, adminEmail, NotificationData{Request: request, Actor: requester})
+}
+
+// NotifyStatusChange tells the requester. Completion and rejection have their own messages.
+func (s *EmailService) NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error {
+	if request.Status == models.StatusCompleted || request.Status == models.StatusRejected {
+		return nil
+	}
+	return s.sendTemplate(models.EventRequestStatusChanged, request.Requester.Email,
+		NotificationData{Request: request, Recipient: &request.Requester, Technician: technician, OldStatus: oldStatus})
+}
+
+func (s *EmailService) NotifyAssignment(request *models.RepairRequest, technician *models.User) error {
+	return s.sendTemplate(models.EventRequestAssigned, technician.Email,
+		NotificationData{Request: request, Recipient: technician, Technician: technician})
+}
+
+func (s *EmailService) NotifyCompletion(request *models.RepairRequest, technician *models.User) error {
+	return s.sendTemplate(models.EventRequestCompleted, request.Requester.Email,
+		NotificationData{Request: request, Recipient: &request.Requester, Technician: technician})
+}
+
+func (s *EmailService) NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error {
+	return s.sendTemplate(models.EventRequestRejected, request.Requester.Email,
+		NotificationData{Request: request, Recipient: &request.Requester, Actor: admin, Reason: reason})
+}
+
+// NotifyNewComment implements CommentNotifier
+func (s *EmailService) NotifyNewComment(request *models.RepairRequest, comment *models.Comment, author *models.User, recipient *models.User) error {
+	if recipient == nil {
+		return nil
+	}
+	return s.sendTemplate(models.EventCommentAdded, recipient.Email,
+		NotificationData{Request: request, Recipient: recipient, Actor: author, Comment: comment})
+}
+
+// NotifyApproval implements ApprovalNotifier
+func (s *EmailService) NotifyApproval(request *models.RepairRequest, approval *models.Approval, oldStatus string) error {
+	return s.sendTemplate(models.EventRequestApproval, request.Requester.Email,
+		NotificationData{Request: request, Recipient: &request.Requester, Actor: &approval.Approver, Approval: approval, OldStatus: oldStatus})
+}
+
+// NotifyReopen implements ReopenNotifier
+func (s *EmailService) NotifyReopen(request *models.RepairRequest, technician *models.User, reason string) error {
+	if technician == nil {
+		return nil
+	}
+	return s.sendTemplate(models.EventRequestReopened, technician.Email,
+		NotificationData{Request: request, Recipient: technician, Technician: technician, Actor: &request.Requester, Reason: reason, OldStatus: string(models.StatusCompleted)})
+}
+
+// NotifySignOff implements SignOffNotifier
+func (s *EmailService) NotifySignOff(request *models.RepairRequest, technician *models.User, requester *models.User) error {
+	if technician == nil {
+		return nil
+	}
+	return s.sendTemplate(models.EventRequestSignedOff, technician.Email,
+		NotificationData{Request: request, Recipient: technician, Technician: technician, Actor: requester, Reason: request.DisputeReason})
+}
+
+// Send delivers a message with both a plain text and an HTML body
+func (s *EmailService) Send(config SMTPConfig, to []string, subject, text, html string) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return errors.New("no recipients")
+	}
+
+	from, _ := mail.ParseAddress(config.From)
+	recipients := make([]string, 0, len(to))
+	for _, address := range to {
+		parsed, err := mail.ParseAddress(address)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %v", address, err)
+		}
+		recipients = append(recipients, parsed.Address)
+	}
+
+	message, err := s.buildMessage(config.From, to, subject, text, html)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	tlsConfig := &tls.Config{ServerName: config.Host}
+
+	var conn net.Conn
+	if config.TLSMode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %v", err)
+	}
+	defer client.Close()
+
+	if config.TLSMode == SMTPTLSStartTLS {
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %v", err)
+		}
+	}
+	if config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", config.Username, config.Password, config.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %v", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %v", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("SMTP server rejected recipient %s: %v", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %v", err)
+	}
+	return client.Quit()
+}
+
+// sendTemplate renders the event's template and mails it to a single recipient.
+// Users without an email address are skipped.
+func (s *EmailService) sendTemplate(event, to string, data NotificationData) error {
+	if !s.IsEnabled() || to == "" {
+		return nil
+	}
+
+	rendered, err := s.templates.RenderEvent(s.Channel(), event, data)
+	if err != nil {
+		return err
+	}
+	return s.Send(s.Config(), []string{to}, rendered.Subject, rendered.Body, rendered.HTML)
+}
+
+// buildMessage encodes a multipart/alternative message with quoted-printable parts
+func (s *EmailService) buildMessage(from string, to []string, subject, text, html string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		w, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", from)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", writer.Boundary())
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"repair-system/models"
+)
+
+// smtpSink is a minimal SMTP server that accepts one session and records what it was sent
+type smtpSink struct {
+	listener net.Listener
+	auth     string
+	from     string
+	rcpt     []string
+	data     []byte
+	done     chan struct{}
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	sink := &smtpSink{listener: listener, done: make(chan struct{})}
+	t.Cleanup(func() { listener.Close() })
+	go sink.serve()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			text.PrintfLine("%s", line)
+		}
+	}
+	reply("220 sink ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			reply("250-sink", "250-AUTH PLAIN", "250 8BITMIME")
+		case "AUTH":
+			s.auth = arg
+			reply("235 2.7.0 Authenticated")
+		case "MAIL":
+			s.from = arg
+			reply("250 2.1.0 OK")
+		case "RCPT":
+			s.rcpt = append(s.rcpt, arg)
+			reply("250 2.1.5 OK")
+		case "DATA":
+			reply("354 Go ahead")
+			if s.data, err = text.ReadDotBytes(); err != nil {
+				return
+			}
+			reply("250 2.0.0 Queued")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			reply("502 5.5.2 Unknown command")
+		}
+	}
+}
+
+func sinkConfig(sink *smtpSink, tlsMode string) SMTPConfig {
+	return SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    sink.port(),
+		From:    "Repairs <repairs@example.com>",
+		TLSMode: tlsMode,
+	}
+}
+
+func TestSendPlain(t *testing.T) {
+	sink := newSMTPSink(t)
+	config := sinkConfig(sink, SMTPTLSNone)
+	config.Username = "mailer"
+	config.Password = "secret"
+
+	subject := "งานซ่อม #7 เสร็จแล้ว"
+	text := "สวัสดี\nงานของคุณเสร็จแล้ว"
+	html := `<p style="color: red">เสร็จแล้ว</p>`
+	err := (&EmailService{}).Send(config, []string{"Somchai <somchai@example.com>"}, subject, text, html)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-sink.done
+
+	if !strings.HasPrefix(sink.auth, "PLAIN") {
+		t.Errorf("auth = %q, want PLAIN", sink.auth)
+	}
+	if !strings.HasPrefix(sink.from, "FROM:<repairs@example.com>") {
+		t.Errorf("MAIL %s", sink.from)
+	}
+	if len(sink.rcpt) != 1 || sink.rcpt[0] != "TO:<somchai@example.com>" {
+		t.Errorf("RCPT %v", sink.rcpt)
+	}
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(string(sink.data))))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	decoded, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil || decoded != subject {
+		t.Errorf("subject = %q (%v), want %q", decoded, err, subject)
+	}
+	if to := message.Header.Get("To"); to != "Somchai <somchai@example.com>" {
+		t.Errorf("To = %q", to)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q (%v)", mediaType, err)
+	}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for _, want := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		if got := part.Header.Get("Content-Type"); got != want.contentType {
+			t.Errorf("part content type = %q, want %q", got, want.contentType)
+		}
+		// NextPart has already undone the quoted-printable encoding
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		if got := strings.ReplaceAll(string(body), "\r\n", "\n"); got != want.body {
+			t.Errorf("part body = %q, want %q", got, want.body)
+		}
+	}
+	if _, err := reader.NextPart(); err != io.EOF {
+		t.Errorf("expected two parts, got another (%v)", err)
+	}
+}
+
+func TestSendTLSModesAgainstPlainServer(t *testing.T) {
+	tests := []struct {
+		tlsMode string
+		want    string
+	}{
+		// The sink does not offer STARTTLS, so the upgrade must fail rather than fall back to plain text
+		{SMTPTLSStartTLS, "STARTTLS failed"},
+		// Implicit TLS gets a plain greeting instead of a handshake
+		{SMTPTLSImplicit, "failed to connect to SMTP server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tlsMode, func(t *testing.T) {
+			sink := newSMTPSink(t)
+			err := (&EmailService{}).Send(sinkConfig(sink, tt.tlsMode), []string{"a@example.com"}, "s", "t", "h")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Send = %v, want error containing %q", err, tt.want)
+			}
+			<-sink.done
+			if sink.data != nil {
+				t.Error("message was delivered over a plain connection")
+			}
+		})
+	}
+}
+
+func TestSendRejectsInvalidInput(t *testing.T) {
+	config := SMTPConfig{Host: "127.0.0.1", Port: 25, From: "repairs@example.com", TLSMode: SMTPTLSNone}
+	tests := []struct {
+		name   string
+		config SMTPConfig
+		to     []string
+		want   string
+	}{
+		{"no recipients", config, nil, "no recipients"},
+		{"bad recipient", config, []string{"not an address"}, "invalid recipient"},
+		{"bad TLS mode", SMTPConfig{Host: "127.0.0.1", Port: 25, From: "repairs@example.com", TLSMode: "ssl"}, []string{"a@example.com"}, "unknown TLS mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&EmailService{}).Send(tt.config, tt.to, "s", "t", "h")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Send = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildMessageHeaders(t *testing.T) {
+	message, err := (&EmailService{}).buildMessage("repairs@example.com", []string{"a@example.com", "b@example.com"}, "Plain subject", "t", "h")
+	if err != nil {
+		t.Fatalf("buildMessage: %v", err)
+	}
+	header, _, _ := strings.Cut(string(message), "\r\n\r\n")
+	for _, want := range []string{
+		"From: repairs@example.com",
+		"To: a@example.com, b@example.com",
+		"Subject: Plain subject",
+		"MIME-Version: 1.0",
+	} {
+		if !strings.Contains(header, want+"\r\n") {
+			t.Errorf("header missing %q:\n%s", want, header)
+		}
+	}
+}
+
+func TestNotifyStatusChangeSkipsFinalStatuses(t *testing.T) {
+	// Completion and rejection are sent by their own events. Returning before the
+	// settings are read means the nil settings service is never touched.
+	service := &EmailService{}
+	for _, status := range []models.RepairStatus{models.StatusCompleted, models.StatusRejected} {
+		request := &models.RepairRequest{Status: status}
+		if err := service.NotifyStatusChange(request, string(models.StatusInProgress), nil); err != nil {
+			t.Errorf("%s: %v", status, err)
+		}
+	}
+}
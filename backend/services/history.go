@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// Fields tracked in the repair request history
+const (
+	HistoryFieldStatus     = "status"
+	HistoryFieldTechnician = "technicianId"
+	HistoryFieldPriority   = "priority"
+	HistoryFieldCost       = "cost"
+	HistoryFieldSignOff    = "signOff"
+)
+
+type HistoryService struct{}
+
+func NewHistoryService() *HistoryService {
+	return &HistoryService{}
+}
+
+// RecordChange appends a history event unless the value did not change.
+// Pass the transaction that performs the change so both commit together.
+func (s *HistoryService) RecordChange(tx *gorm.DB, requestID uint, actorID *uint, field, oldValue, newValue string) error {
+	if oldValue == newValue {
+		return nil
+	}
+
+	event := models.RepairRequestEvent{
+		RepairRequestID: requestID,
+		ActorID:         actorID,
+		Field:           field,
+		OldValue:        oldValue,
+		NewValue:        newValue,
+	}
+	return tx.Create(&event).Error
+}
+
+// RecordChanges appends an event for every tracked field that differs between before and after
+func (s *HistoryService) RecordChanges(tx *gorm.DB, before, after *models.RepairRequest, actorID *uint) error {
+	changes := []struct {
+		field    string
+		oldValue string
+		newValue string
+	}{
+		{HistoryFieldStatus, string(before.Status), string(after.Status)},
+		{HistoryFieldTechnician, s.FormatID(before.TechnicianID), s.FormatID(after.TechnicianID)},
+		{HistoryFieldPriority, string(before.Priority), string(after.Priority)},
+		{HistoryFieldCost, s.FormatCost(before.Cost), s.FormatCost(after.Cost)},
+		{HistoryFieldSignOff, string(before.SignOff), string(after.SignOff)},
+	}
+
+	for _, change := range changes {
+		if err := s.RecordChange(tx, after.ID, actorID, change.field, change.oldValue, change.newValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Events returns the history of a request in chronological order
+func (s *HistoryService) Events(db *gorm.DB, requestID uint) ([]models.RepairRequestEvent, error) {
+	events := []models.RepairRequestEvent{}
+	err := db.Preload("Actor").
+		Where("repair_request_id = ?", requestID).
+		Order("created_at ASC, id ASC").
+		Find(&events).Error
+	return events, err
+}
+
+// StatusDurations adds up how long the request spent in each status, based on its status
+// events. The current status is counted up to now.
+func (s *HistoryService) StatusDurations(events []models.RepairRequestEvent, now time.Time) map[models.RepairStatus]time.Duration {
+	durations := map[models.RepairStatus]time.Duration{}
+
+	var current models.RepairStatus
+	var since time.Time
+	for _, event := range events {
+		if event.Field != HistoryFieldStatus {
+			continue
+		}
+		if current != "" {
+			durations[current] += event.CreatedAt.Sub(since)
+		}
+		current = models.RepairStatus(event.NewValue)
+		since = event.CreatedAt
+	}
+
+	// Final states stop the clock
+	if current != "" && current != models.StatusCompleted && current != models.StatusRejected {
+		durations[current] += now.Sub(since)
+	}
+	return durations
+}
+
+// FormatID renders an optional ID for storage in the history
+func (s *HistoryService) FormatID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d", *id)
+}
+
+// FormatCost renders a cost for storage in the history
+func (s *HistoryService) FormatCost(cost float64) string {
+	return fmt.Sprintf("%.2f", cost)
+}
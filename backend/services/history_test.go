@@ -0,0 +1,108 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"repair-system/models"
+)
+
+func TestRecordChanges(t *testing.T) {
+	db := setupTestDB(t)
+	service := NewHistoryService()
+
+	technicianID := uint(7)
+	actorID := uint(1)
+	before := models.RepairRequest{Status: models.StatusPending, Priority: models.PriorityMedium, Cost: 100}
+	before.ID = 3
+	after := before
+	after.Status = models.StatusInProgress
+	after.TechnicianID = &technicianID
+	after.Cost = 100.004 // Rounds to the same stored value
+
+	if err := service.RecordChanges(db, &before, &after, &actorID); err != nil {
+		t.Fatalf("RecordChanges: %v", err)
+	}
+	// Saving the same values again records nothing
+	if err := service.RecordChanges(db, &after, &after, &actorID); err != nil {
+		t.Fatalf("RecordChanges: %v", err)
+	}
+
+	events, err := service.Events(db, after.ID)
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	want := []struct{ field, oldValue, newValue string }{
+		{HistoryFieldStatus, "pending", "in_progress"},
+		{HistoryFieldTechnician, "", "7"},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("recorded %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, event := range events {
+		if event.Field != want[i].field || event.OldValue != want[i].oldValue || event.NewValue != want[i].newValue {
+			t.Errorf("event %d = %s %q -> %q, want %+v", i, event.Field, event.OldValue, event.NewValue, want[i])
+		}
+		if event.RepairRequestID != after.ID || event.ActorID == nil || *event.ActorID != actorID {
+			t.Errorf("event %d belongs to request %d, actor %v", i, event.RepairRequestID, event.ActorID)
+		}
+	}
+}
+
+func TestStatusDurations(t *testing.T) {
+	start := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	status := func(offset time.Duration, oldValue, newValue models.RepairStatus) models.RepairRequestEvent {
+		return models.RepairRequestEvent{
+			CreatedAt: start.Add(offset),
+			Field:     HistoryFieldStatus,
+			OldValue:  string(oldValue),
+			NewValue:  string(newValue),
+		}
+	}
+	now := start.Add(10 * time.Hour)
+
+	tests := []struct {
+		name   string
+		events []models.RepairRequestEvent
+		want   map[models.RepairStatus]time.Duration
+	}{
+		{"no events", nil, map[models.RepairStatus]time.Duration{}},
+		{"open status runs until now", []models.RepairRequestEvent{
+			status(0, "", models.StatusPending),
+			status(time.Hour, models.StatusPending, models.StatusInProgress),
+		}, map[models.RepairStatus]time.Duration{
+			models.StatusPending:    time.Hour,
+			models.StatusInProgress: 9 * time.Hour,
+		}},
+		{"repeated status adds up", []models.RepairRequestEvent{
+			status(0, "", models.StatusInProgress),
+			status(time.Hour, models.StatusInProgress, models.StatusWaitingPart),
+			status(3*time.Hour, models.StatusWaitingPart, models.StatusInProgress),
+			status(4*time.Hour, models.StatusInProgress, models.StatusCompleted),
+		}, map[models.RepairStatus]time.Duration{
+			models.StatusInProgress:  2 * time.Hour,
+			models.StatusWaitingPart: 2 * time.Hour,
+		}},
+		{"other fields are ignored", []models.RepairRequestEvent{
+			status(0, "", models.StatusPending),
+			{CreatedAt: start.Add(time.Hour), Field: HistoryFieldPriority, OldValue: "low", NewValue: "high"},
+			status(2*time.Hour, models.StatusPending, models.StatusRejected),
+		}, map[models.RepairStatus]time.Duration{
+			models.StatusPending: 2 * time.Hour,
+		}},
+	}
+	service := NewHistoryService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.StatusDurations(tt.events, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("StatusDurations = %v, want %v", got, tt.want)
+			}
+			for status, duration := range tt.want {
+				if got[status] != duration {
+					t.Errorf("%s = %v, want %v", status, got[status], duration)
+				}
+			}
+		})
+	}
+}
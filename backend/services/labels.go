@@ -0,0 +1,102 @@
+package services
+
+// Notification locales
+const (
+	LocaleThai    = "th"
+	LocaleEnglish = "en"
+)
+
+// NotificationLocales lists the locales notification templates can be written in
+var NotificationLocales = []string{LocaleThai, LocaleEnglish}
+
+var priorityLabels = map[string]map[string]string{
+	LocaleThai: {
+		"urgent": "เร่งด่วน",
+		"high":   "สูง",
+		"medium": "ปานกลาง",
+		"low":    "ต่ำ",
+	},
+	LocaleEnglish: {
+		"urgent": "Urgent",
+		"high":   "High",
+		"medium": "Medium",
+		"low":    "Low",
+	},
+}
+
+var statusLabels = map[string]map[string]string{
+	LocaleThai: {
+		"awaiting_approval": "รออนุมัติ",
+		"pending":           "รอดำเนินการ",
+		"in_progress":       "กำลังดำเนินการ",
+		"waiting_part":      "รออะไหล่",
+		"completed":         "เสร็จสิ้น",
+		"rejected":          "ปฏิเสธ",
+	},
+	LocaleEnglish: {
+		"awaiting_approval": "Awaiting approval",
+		"pending":           "Pending",
+		"in_progress":       "In progress",
+		"waiting_part":      "Waiting for parts",
+		"completed":         "Completed",
+		"rejected":          "Rejected",
+	},
+}
+
+// priorityLabel returns the Thai name of a repair priority, shared by every notifier
+func priorityLabel(priority string) string {
+	return localizedPriorityLabel(LocaleThai, priority)
+}
+
+// statusLabel returns the Thai name of a repair status, shared by every notifier
+func statusLabel(status string) string {
+	return localizedStatusLabel(LocaleThai, status)
+}
+
+func localizedPriorityLabel(locale, priority string) string {
+	if label, ok := priorityLabels[locale][priority]; ok {
+		return label
+	}
+	return priority
+}
+
+func localizedStatusLabel(locale, status string) string {
+	if label, ok := statusLabels[locale][status]; ok {
+		return label
+	}
+	return status
+}
+
+func priorityEmoji(priority string) string {
+	switch priority {
+	case "urgent":
+		return "🚨"
+	case "high":
+		return "🔴"
+	case "medium":
+		return "🟡"
+	case "low":
+		return "🟢"
+	default:
+		return "⚪"
+	}
+}
+
+func statusEmoji(status string) string {
+	switch status {
+	case "awaiting_approval":
+		return "📝"
+	case "pending":
+		return "⏳"
+	case "in_progress":
+		return "🔧"
+	case "waiting_part":
+		return "📦"
+	case "completed":
+		return "✅"
+	case "rejected":
+		return "❌"
+	default:
+		return "❓"
+	}
+}
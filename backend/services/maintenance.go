@@ -0,0 +1,71 @@
+package services
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"repair-system/models"
+)
+
+// maintenanceCacheTTL bounds how long a cached maintenance flag is trusted
+const maintenanceCacheTTL = 30 * time.Second
+
+// MaintenanceStatus is the current maintenance mode configuration
+type MaintenanceStatus struct {
+	Enabled    bool   `json:"enabled"`
+	Message    string `json:"message"`
+	RetryAfter int    `json:"retryAfter"` // Seconds
+}
+
+// maintenanceCache is shared by every MaintenanceService so an invalidation is seen everywhere
+var maintenanceCache struct {
+	sync.RWMutex
+	status   MaintenanceStatus
+	loadedAt time.Time
+}
+
+type MaintenanceService struct {
+	settingsService *SettingsService
+}
+
+func NewMaintenanceService(settingsService *SettingsService) *MaintenanceService {
+	return &MaintenanceService{
+		settingsService: settingsService,
+	}
+}
+
+// Status returns the maintenance configuration, reading the settings table at most once per TTL
+func (s *MaintenanceService) Status() MaintenanceStatus {
+	maintenanceCache.RLock()
+	if !maintenanceCache.loadedAt.IsZero() && time.Since(maintenanceCache.loadedAt) < maintenanceCacheTTL {
+		status := maintenanceCache.status
+		maintenanceCache.RUnlock()
+		return status
+	}
+	maintenanceCache.RUnlock()
+
+	retryAfter, err := strconv.Atoi(s.settingsService.GetSettingWithDefault(models.SettingMaintenanceRetryAfter, "300"))
+	if err != nil || retryAfter < 0 {
+		retryAfter = 300
+	}
+	status := MaintenanceStatus{
+		Enabled:    s.settingsService.GetBoolSetting(models.SettingMaintenanceMode),
+		Message:    s.settingsService.GetSettingWithDefault(models.SettingMaintenanceMessage, "System is under maintenance"),
+		RetryAfter: retryAfter,
+	}
+
+	maintenanceCache.Lock()
+	maintenanceCache.status = status
+	maintenanceCache.loadedAt = time.Now()
+	maintenanceCache.Unlock()
+
+	return status
+}
+
+// Invalidate drops the cached status so the next call reads the settings table
+func (s *MaintenanceService) Invalidate() {
+	maintenanceCache.Lock()
+	maintenanceCache.loadedAt = time.Time{}
+	maintenanceCache.Unlock()
+}
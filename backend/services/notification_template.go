@@ -0,0 +1,532 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// Telegram rejects messages longer than this many characters
+const telegramMaxMessageLength = 4096
+
+// NotificationTemplateChannels lists the channels whose messages come from templates
+var NotificationTemplateChannels = []string{"telegram", "email"}
+
+var ErrUnknownNotificationTemplate = errors.New("unknown notification template")
+
+// NotificationTemplateError is returned when a template fails to parse or render
+type NotificationTemplateError struct {
+	Field string // subject, body or html
+	Err   error
+}
+
+func (e *NotificationTemplateError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Field, e.Err)
+}
+
+func (e *NotificationTemplateError) Unwrap() error {
+	return e.Err
+}
+
+// NotificationData is what every notification template renders against
+type NotificationData struct {
+	SiteName   string
+	Time       string // When the message is rendered
+	Locale     string
+	Request    *models.RepairRequest
+	Recipient  *models.User // Nil for the Telegram group
+	Technician *models.User
+	Actor      *models.User // Requester, approver, rejecter or comment author, depending on the event
+	OldStatus  string
+	Reason     string
+	Comment    *models.Comment
+	Approval   *models.Approval
+}
+
+// RenderedNotification is a template's output. Telegram only uses Body.
+type RenderedNotification struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	HTML    string `json:"html"`
+}
+
+// NotificationTemplateView is a template as admins see it, whether built in or saved
+type NotificationTemplateView struct {
+	models.NotificationTemplate
+	Customized bool `json:"customized"` // False while the built-in template is in use
+}
+
+// NotificationTemplateService loads, validates and renders notification templates
+type NotificationTemplateService struct {
+	settingsService *SettingsService
+}
+
+func NewNotificationTemplateService(settingsService *SettingsService) *NotificationTemplateService {
+	return &NotificationTemplateService{settingsService: settingsService}
+}
+
+// Locale returns the locale notifications are sent in
+func (s *NotificationTemplateService) Locale() string {
+	locale := s.settingsService.GetSettingWithDefault(models.SettingNotificationLocale, LocaleThai)
+	if !IsNotificationLocale(locale) {
+		return LocaleThai
+	}
+	return locale
+}
+
+// IsNotificationLocale reports whether templates exist for the locale
+func IsNotificationLocale(locale string) bool {
+	for _, l := range NotificationLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Get returns the saved template for the channel, event and locale, or the built-in one
+func (s *NotificationTemplateService) Get(channel, event, locale string) (*NotificationTemplateView, error) {
+	builtIn, ok := defaultNotificationTemplates[channel][locale][event]
+	if !ok {
+		return nil, ErrUnknownNotificationTemplate
+	}
+
+	var saved models.NotificationTemplate
+	err := config.DB.Where("channel = ? AND event = ? AND locale = ?", channel, event, locale).First(&saved).Error
+	if err == nil {
+		return &NotificationTemplateView{NotificationTemplate: saved, Customized: true}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	builtIn.Channel, builtIn.Event, builtIn.Locale = channel, event, locale
+	return &NotificationTemplateView{NotificationTemplate: builtIn}, nil
+}
+
+// List returns every template in channel, locale and event order
+func (s *NotificationTemplateService) List() ([]NotificationTemplateView, error) {
+	views := []NotificationTemplateView{}
+	for _, channel := range NotificationTemplateChannels {
+		for _, locale := range NotificationLocales {
+			for _, event := range NotificationEvents {
+				view, err := s.Get(channel, event, locale)
+				if err != nil {
+					return nil, err
+				}
+				views = append(views, *view)
+			}
+		}
+	}
+	return views, nil
+}
+
+// Save validates the template and stores it in place of the current one
+func (s *NotificationTemplateService) Save(template *models.NotificationTemplate) error {
+	if _, ok := defaultNotificationTemplates[template.Channel][template.Locale][template.Event]; !ok {
+		return ErrUnknownNotificationTemplate
+	}
+	if err := s.Validate(template); err != nil {
+		return err
+	}
+
+	var existing models.NotificationTemplate
+	err := config.DB.Where("channel = ? AND event = ? AND locale = ?", template.Channel, template.Event, template.Locale).
+		First(&existing).Error
+	if err == nil {
+		template.ID = existing.ID
+		template.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return config.DB.Save(template).Error
+}
+
+// Reset deletes the saved template so the built-in one is used again
+func (s *NotificationTemplateService) Reset(channel, event, locale string) error {
+	if _, ok := defaultNotificationTemplates[channel][locale][event]; !ok {
+		return ErrUnknownNotificationTemplate
+	}
+	return config.DB.Where("channel = ? AND event = ? AND locale = ?", channel, event, locale).
+		Delete(&models.NotificationTemplate{}).Error
+}
+
+// Validate checks that the template has the parts its channel needs and renders
+// against sample data for its event
+func (s *NotificationTemplateService) Validate(template *models.NotificationTemplate) error {
+	if strings.TrimSpace(template.Body) == "" {
+		return &NotificationTemplateError{Field: "body", Err: errors.New("is required")}
+	}
+	if template.Channel == "email" {
+		if strings.TrimSpace(template.Subject) == "" {
+			return &NotificationTemplateError{Field: "subject", Err: errors.New("is required")}
+		}
+		if strings.TrimSpace(template.HTML) == "" {
+			return &NotificationTemplateError{Field: "html", Err: errors.New("is required")}
+		}
+	}
+
+	// Render every variant of the sample, so both branches of e.g. .Approval.Approved are checked
+	samples := s.sampleVariants(template.Event, template.Locale)
+	for _, data := range withoutOptionalUsers(template.Channel, template.Event, samples) {
+		rendered, err := s.Render(template, data)
+		if err != nil {
+			return err
+		}
+		if template.Channel == "telegram" && utf8.RuneCountInString(rendered.Body) > telegramMaxMessageLength {
+			return &NotificationTemplateError{Field: "body", Err: fmt.Errorf("renders longer than Telegram's %d character limit", telegramMaxMessageLength)}
+		}
+	}
+	return nil
+}
+
+// RenderEvent renders the current template for the event in the configured locale
+func (s *NotificationTemplateService) RenderEvent(channel, event string, data NotificationData) (*RenderedNotification, error) {
+	data.Locale = s.Locale()
+	data.SiteName = s.settingsService.GetSettingWithDefault(models.SettingSiteName, "Repair System")
+	data.Time = time.Now().Format("02/01/2006 15:04")
+
+	template, err := s.Get(channel, event, data.Locale)
+	if err != nil {
+		return nil, err
+	}
+	return s.Render(&template.NotificationTemplate, data)
+}
+
+// Render renders a template, which need not be saved. Telegram bodies and email HTML
+// use html/template; email subjects and plain text use text/template.
+func (s *NotificationTemplateService) Render(template *models.NotificationTemplate, data NotificationData) (*RenderedNotification, error) {
+	funcs := notificationTemplateFuncs(template.Locale)
+	view := newNotificationView(data)
+	rendered := &RenderedNotification{}
+
+	if template.Channel == "telegram" {
+		body, err := renderHTMLTemplate(funcs, "", template.Body, view)
+		if err != nil {
+			return nil, &NotificationTemplateError{Field: "body", Err: err}
+		}
+		rendered.Body = strings.TrimSpace(body)
+		return rendered, nil
+	}
+
+	subject, err := renderTextTemplate(funcs, template.Subject, view)
+	if err != nil {
+		return nil, &NotificationTemplateError{Field: "subject", Err: err}
+	}
+	if strings.ContainsAny(subject, "\r\n") {
+		return nil, &NotificationTemplateError{Field: "subject", Err: errors.New("must render to a single line")}
+	}
+	body, err := renderTextTemplate(funcs, template.Body, view)
+	if err != nil {
+		return nil, &NotificationTemplateError{Field: "body", Err: err}
+	}
+	html, err := renderHTMLTemplate(funcs, emailLayouts[template.Locale], template.HTML, view)
+	if err != nil {
+		return nil, &NotificationTemplateError{Field: "html", Err: err}
+	}
+
+	rendered.Subject = strings.TrimSpace(subject)
+	rendered.Body = body
+	rendered.HTML = html
+	return rendered, nil
+}
+
+// SampleData returns the data a preview renders against. When request is nil a made-up
+// request is used.
+func (s *NotificationTemplateService) SampleData(event, locale string, request *models.RepairRequest) NotificationData {
+	data := s.sampleVariants(event, locale)[0]
+	if request != nil {
+		data.Request = request
+		data.Technician = request.Technician
+		data.Recipient = &request.Requester
+		if event == models.EventRequestCreated {
+			data.Actor = &request.Requester
+		}
+		if event == models.EventRequestAssigned && request.Technician != nil {
+			data.Recipient = request.Technician
+		}
+		if request.RejectionReason != "" {
+			data.Reason = request.RejectionReason
+		}
+		if event == models.EventRequestSignedOff {
+			if request.Technician != nil {
+				data.Recipient = request.Technician
+			}
+			data.Actor = &request.Requester
+			data.Reason = request.DisputeReason
+		}
+		if event == models.EventRequestReopened {
+			data.Actor = &request.Requester
+			if request.Technician != nil {
+				data.Recipient = request.Technician
+			}
+			if request.ReopenReason != "" {
+				data.Reason = request.ReopenReason
+			}
+		}
+	}
+	return data
+}
+
+// withoutOptionalUsers adds a copy of each sample without the users a real delivery may
+// lack. Telegram's group chat has no recipient. Events that can happen before a technician
+// is assigned, and whose notifiers never pass one, have no technician either.
+func withoutOptionalUsers(channel, event string, samples []NotificationData) []NotificationData {
+	noRecipient := channel == "telegram"
+	unassigned := false
+	switch event {
+	case models.EventRequestStatusChanged, models.EventRequestRejected, models.EventRequestApproval, models.EventCommentAdded:
+		unassigned = true
+	}
+
+	variants := append([]NotificationData{}, samples...)
+	for _, data := range samples {
+		if (!noRecipient || data.Recipient == nil) && (!unassigned || data.Technician == nil) {
+			continue
+		}
+		variant := data
+		if noRecipient {
+			variant.Recipient = nil
+		}
+		if unassigned {
+			request := *data.Request
+			request.TechnicianID, request.Technician = nil, nil
+			variant.Request, variant.Technician = &request, nil
+		}
+		variants = append(variants, variant)
+	}
+	return variants
+}
+
+// sampleVariants builds sample data for the event; approvals get an approved and a
+// rejected variant, sign-offs an accepted, a disputed and an auto-closed one
+func (s *NotificationTemplateService) sampleVariants(event, locale string) []NotificationData {
+	name := func(th, en string) string {
+		if locale == LocaleEnglish {
+			return en
+		}
+		return th
+	}
+
+	now := time.Now()
+	createdAt := now.Add(-26 * time.Hour)
+	requester := &models.User{ID: 3, Username: "somchai", FullName: name("สมชาย ใจดี", "Somchai Jaidee"), Email: "somchai@example.com", Role: models.RoleRequester}
+	technician := &models.User{ID: 2, Username: "somsak", FullName: name("สมศักดิ์ ช่างดี", "Somsak Changdee"), Email: "somsak@example.com", Role: models.RoleTechnician}
+	admin := &models.User{ID: 1, Username: "admin", FullName: name("ผู้ดูแลระบบ", "Administrator"), Email: "admin@example.com", Role: models.RoleAdmin}
+
+	request := &models.RepairRequest{
+		ID:           123,
+		CreatedAt:    createdAt,
+		Title:        name("เครื่องปรับอากาศไม่เย็น", "Air conditioner not cooling"),
+		Description:  name("เครื่องปรับอากาศห้องประชุมชั้น 3 เปิดแล้วมีแต่ลม ไม่เย็น", "The meeting room AC on the 3rd floor only blows warm air"),
+		Location:     name("ห้องประชุม 3A", "Meeting room 3A"),
+		Priority:     models.PriorityHigh,
+		Status:       models.StatusInProgress,
+		CategoryID:   1,
+		Category:     models.Category{ID: 1, Name: name("เครื่องปรับอากาศ", "Air conditioning")},
+		RequesterID:  requester.ID,
+		Requester:    *requester,
+		TechnicianID: &technician.ID,
+		Technician:   technician,
+		Cost:         1250,
+	}
+
+	data := NotificationData{
+		SiteName:   s.settingsService.GetSettingWithDefault(models.SettingSiteName, "Repair System"),
+		Time:       now.Format("02/01/2006 15:04"),
+		Locale:     locale,
+		Request:    request,
+		Recipient:  requester,
+		Technician: technician,
+		Actor:      technician,
+		OldStatus:  string(models.StatusPending),
+	}
+
+	switch event {
+	case models.EventRequestCreated:
+		request.Status = models.StatusPending
+		request.TechnicianID, request.Technician, data.Technician = nil, nil, nil
+		data.Recipient, data.Actor, data.OldStatus = nil, requester, ""
+	case models.EventRequestAssigned:
+		request.Status = models.StatusPending
+		data.Recipient, data.Actor = technician, admin
+	case models.EventRequestCompleted:
+		request.Status = models.StatusCompleted
+		request.CompletedAt = &now
+		data.OldStatus = string(models.StatusInProgress)
+	case models.EventRequestReopened:
+		request.Status = models.StatusPending
+		request.ReopenCount = 1
+		request.ReopenReason = name("แอร์กลับมาไม่เย็นอีกแล้วหลังซ่อมได้สองวัน", "The AC stopped cooling again two days after the repair")
+		request.ReopenedAt = &now
+		data.Recipient, data.Actor = technician, requester
+		data.Reason, data.OldStatus = request.ReopenReason, string(models.StatusCompleted)
+	case models.EventRequestSignedOff:
+		rating := 5
+		request.Status = models.StatusCompleted
+		request.CompletedAt = &now
+		request.SignOff, request.SignedOffAt = models.SignOffAccepted, &now
+		request.Rating, request.RatingComment = &rating, name("ช่างมาตรงเวลา ทำงานเรียบร้อย", "On time and tidy work")
+		data.Recipient, data.Actor, data.OldStatus = technician, requester, ""
+
+		disputedData := data
+		disputedRequest := *request
+		disputedRequest.SignOff, disputedRequest.Rating, disputedRequest.RatingComment = models.SignOffDisputed, nil, ""
+		disputedRequest.DisputeReason = name("ยังมีน้ำหยดจากเครื่อง", "The unit is still dripping water")
+		disputedData.Request, disputedData.Reason = &disputedRequest, disputedRequest.DisputeReason
+
+		closedData := data
+		closedRequest := *request
+		closedRequest.SignOff, closedRequest.Rating, closedRequest.RatingComment = models.SignOffAutoClosed, nil, ""
+		closedData.Request, closedData.Actor = &closedRequest, nil
+		return []NotificationData{data, disputedData, closedData}
+	case models.EventRequestRejected:
+		request.Status = models.StatusRejected
+		request.RejectionReason = name("อุปกรณ์อยู่ในประกัน กรุณาติดต่อผู้ขาย", "The unit is under warranty, please contact the vendor")
+		data.Reason, data.Actor = request.RejectionReason, admin
+	case models.EventRequestApproval:
+		approved := models.Approval{ID: 1, CreatedAt: now, RepairRequestID: request.ID, ApproverID: admin.ID, Approver: *admin, Approved: true}
+		rejected := approved
+		rejected.Approved = false
+		rejected.Note = name("งบประมาณไม่เพียงพอ", "Not within this quarter's budget")
+
+		request.Status = models.StatusPending
+		data.Actor, data.Approval, data.OldStatus = admin, &approved, string(models.StatusAwaitingApproval)
+		rejectedData := data
+		rejectedRequest := *request
+		rejectedRequest.Status = models.StatusRejected
+		rejectedData.Request, rejectedData.Approval = &rejectedRequest, &rejected
+		return []NotificationData{data, rejectedData}
+	case models.EventCommentAdded:
+		data.Comment = &models.Comment{
+			ID:              1,
+			CreatedAt:       now,
+			RepairRequestID: request.ID,
+			UserID:          technician.ID,
+			User:            *technician,
+			Content:         name("สั่งคอมเพรสเซอร์แล้ว คาดว่าจะได้รับภายใน 2 วัน", "Compressor ordered, expected within 2 days"),
+		}
+	}
+	return []NotificationData{data}
+}
+
+func renderTextTemplate(funcs map[string]interface{}, source string, view notificationView) (string, error) {
+	tmpl, err := texttemplate.New("template").Funcs(funcs).Parse(source)
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tmpl.Templates() {
+		if err := checkTemplateFields(t.Root); err != nil {
+			return "", err
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, view); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// renderHTMLTemplate renders source on its own, or as "content" inside layout when one is given
+func renderHTMLTemplate(funcs map[string]interface{}, layout, source string, view notificationView) (string, error) {
+	var tmpl *htmltemplate.Template
+	var err error
+	if layout == "" {
+		tmpl, err = htmltemplate.New("template").Funcs(funcs).Parse(source)
+	} else {
+		tmpl, err = htmltemplate.New("layout").Funcs(funcs).Parse(layout)
+		if err == nil {
+			_, err = tmpl.New("content").Parse(source)
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	for _, t := range tmpl.Templates() {
+		if err := checkTemplateFields(t.Tree.Root); err != nil {
+			return "", err
+		}
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, view); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// notificationTemplateFuncs are the helpers available to every template
+func notificationTemplateFuncs(locale string) map[string]interface{} {
+	return map[string]interface{}{
+		"statusText":    func(status interface{}) string { return localizedStatusLabel(locale, fmt.Sprint(status)) },
+		"priorityText":  func(priority interface{}) string { return localizedPriorityLabel(locale, fmt.Sprint(priority)) },
+		"statusEmoji":   func(status interface{}) string { return statusEmoji(fmt.Sprint(status)) },
+		"priorityEmoji": func(priority interface{}) string { return priorityEmoji(fmt.Sprint(priority)) },
+		"lower":         func(value interface{}) string { return strings.ToLower(fmt.Sprint(value)) },
+		"formatTime":    formatTemplateTime,
+		"truncate":      truncateRunes,
+		"duration": func(from time.Time, to *time.Time) string {
+			if to == nil || from.IsZero() {
+				return "-"
+			}
+			return localizedDuration(locale, to.Sub(from))
+		},
+		"money": func(amount float64) string {
+			if amount <= 0 {
+				if locale == LocaleEnglish {
+					return "No charge"
+				}
+				return "ไม่มีค่าใช้จ่าย"
+			}
+			if locale == LocaleEnglish {
+				return fmt.Sprintf("%.2f THB", amount)
+			}
+			return fmt.Sprintf("%.2f บาท", amount)
+		},
+	}
+}
+
+// formatTemplateTime formats a time.Time or *time.Time; nil renders as "-"
+func formatTemplateTime(value interface{}) string {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format("02/01/2006 15:04")
+	case *time.Time:
+		if t != nil {
+			return t.Format("02/01/2006 15:04")
+		}
+	}
+	return "-"
+}
+
+// truncateRunes shortens text to max characters without splitting a Thai character
+func truncateRunes(max int, text string) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "..."
+}
+
+func localizedDuration(locale string, d time.Duration) string {
+	hours := int(d.Hours())
+	value, unit := hours/24, [2]string{"วัน", "days"}
+	if hours < 1 {
+		value, unit = int(d.Minutes()), [2]string{"นาที", "minutes"}
+	} else if hours < 24 {
+		value, unit = hours, [2]string{"ชั่วโมง", "hours"}
+	}
+	if locale == LocaleEnglish {
+		return fmt.Sprintf("%d %s", value, unit[1])
+	}
+	return fmt.Sprintf("%d %s", value, unit[0])
+}
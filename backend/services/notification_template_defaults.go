@@ -0,0 +1,505 @@
+package services
+
+import "repair-system/models"
+
+// Built-in templates, used until an admin saves a replacement. Telegram bodies and
+// email HTML are html/template, so values are escaped; email subjects and plain text
+// bodies are text/template. Every template renders against NotificationData.
+
+// emailLayouts wrap every email HTML body; the event template is defined as "content"
+var emailLayouts = map[string]string{
+	LocaleThai: `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #333; line-height: 1.5;">
+<div style="max-width: 600px; margin: 0 auto;">
+<h2 style="color: #1976d2;">{{.SiteName}}</h2>
+{{template "content" .}}
+{{with .Request}}<table style="border-collapse: collapse; margin-top: 16px;">
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">เลขที่</td><td>#{{.ID}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">หัวข้อ</td><td>{{.Title}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">หมวดหมู่</td><td>{{.Category.Name}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">ความสำคัญ</td><td>{{priorityText .Priority}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">สถานะ</td><td>{{statusText .Status}}</td></tr>
+{{if .Location}}<tr><td style="padding: 4px 12px 4px 0; color: #777;">สถานที่</td><td>{{.Location}}</td></tr>{{end}}
+</table>{{end}}
+<p style="margin-top: 24px; font-size: 12px; color: #999;">{{.Time}}</p>
+</div>
+</body>
+</html>`,
+	LocaleEnglish: `<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #333; line-height: 1.5;">
+<div style="max-width: 600px; margin: 0 auto;">
+<h2 style="color: #1976d2;">{{.SiteName}}</h2>
+{{template "content" .}}
+{{with .Request}}<table style="border-collapse: collapse; margin-top: 16px;">
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">Number</td><td>#{{.ID}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">Title</td><td>{{.Title}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">Category</td><td>{{.Category.Name}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">Priority</td><td>{{priorityText .Priority}}</td></tr>
+<tr><td style="padding: 4px 12px 4px 0; color: #777;">Status</td><td>{{statusText .Status}}</td></tr>
+{{if .Location}}<tr><td style="padding: 4px 12px 4px 0; color: #777;">Location</td><td>{{.Location}}</td></tr>{{end}}
+</table>{{end}}
+<p style="margin-top: 24px; font-size: 12px; color: #999;">{{.Time}}</p>
+</div>
+</body>
+</html>`,
+}
+
+const emailTextFooterTH = `
+เลขที่: #{{.Request.ID}}
+หัวข้อ: {{.Request.Title}}
+หมวดหมู่: {{.Request.Category.Name}}
+ความสำคัญ: {{priorityText .Request.Priority}}
+สถานะ: {{statusText .Request.Status}}
+{{if .Request.Location}}สถานที่: {{.Request.Location}}
+{{end}}
+{{.SiteName}} - {{.Time}}
+`
+
+const emailTextFooterEN = `
+Number: #{{.Request.ID}}
+Title: {{.Request.Title}}
+Category: {{.Request.Category.Name}}
+Priority: {{priorityText .Request.Priority}}
+Status: {{statusText .Request.Status}}
+{{if .Request.Location}}Location: {{.Request.Location}}
+{{end}}
+{{.SiteName}} - {{.Time}}
+`
+
+// defaultNotificationTemplates is indexed by channel, locale and event
+var defaultNotificationTemplates = map[string]map[string]map[string]models.NotificationTemplate{
+	"telegram": {
+		LocaleThai: {
+			models.EventRequestCreated: {Body: `🔧 <b>แจ้งซ่อมใหม่</b>
+
+{{priorityEmoji .Request.Priority}} <b>{{.Request.Title}}</b> {{statusEmoji .Request.Status}}
+
+📋 <b>รายละเอียด:</b>
+• หัวข้อ: {{.Request.Title}}
+• รายละเอียด: {{truncate 100 .Request.Description}}
+• สถานที่: {{or .Request.Location "ไม่ระบุ"}}
+• ระดับความสำคัญ: {{priorityText .Request.Priority}} {{priorityEmoji .Request.Priority}}
+• สถานะ: {{statusText .Request.Status}} {{statusEmoji .Request.Status}}
+
+👤 <b>ผู้แจ้ง:</b> {{.Actor.FullName}} ({{.Actor.Username}})
+🕐 <b>เวลา:</b> {{formatTime .Request.CreatedAt}}
+
+#แจ้งซ่อม #ใหม่ #{{lower .Request.Priority}}`},
+			models.EventRequestStatusChanged: {Body: `🔄 <b>เปลี่ยนสถานะงานซ่อม</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+
+🔄 <b>สถานะ:</b>
+{{statusEmoji .OldStatus}} {{statusText .OldStatus}} ➡️ {{statusEmoji .Request.Status}} {{statusText .Request.Status}}
+
+👤 <b>ช่าง:</b> {{with .Technician}}{{.FullName}}{{else}}ยังไม่ได้มอบหมาย{{end}}
+🕐 <b>เวลา:</b> {{.Time}}
+
+#เปลี่ยนสถานะ #{{lower .Request.Status}}`},
+			models.EventRequestAssigned: {Body: `👷‍♂️ <b>มอบหมายงานซ่อม</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+🔧 <b>ช่างที่รับผิดชอบ:</b> {{.Technician.FullName}}
+⚡ <b>ระดับความสำคัญ:</b> {{priorityText .Request.Priority}} {{priorityEmoji .Request.Priority}}
+
+📍 <b>สถานที่:</b> {{or .Request.Location "ไม่ระบุ"}}
+📅 <b>เวลาที่มอบหมาย:</b> {{.Time}}
+
+#มอบหมายงาน #{{.Technician.Username}}`},
+			models.EventRequestCompleted: {Body: `✅ <b>งานซ่อมเสร็จสิ้น</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+🔧 <b>ช่าง:</b> {{.Technician.FullName}}
+⏱️ <b>ระยะเวลา:</b> {{duration .Request.CreatedAt .Request.CompletedAt}}
+💰 <b>ค่าใช้จ่าย:</b> {{money .Request.Cost}}
+
+📅 <b>เสร็จสิ้นเมื่อ:</b> {{formatTime .Request.CompletedAt}}
+
+#เสร็จสิ้น #สำเร็จ`},
+			models.EventRequestReopened: {Body: `🔁 <b>เปิดงานซ่อมอีกครั้ง</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+👤 <b>ผู้แจ้ง:</b> {{.Actor.FullName}}
+🔧 <b>ช่างคนล่าสุด:</b> {{with .Technician}}{{.FullName}}{{else}}ไม่มี{{end}}
+🔢 <b>เปิดใหม่ครั้งที่:</b> {{.Request.ReopenCount}}
+
+📝 <b>เหตุผล:</b>
+{{.Reason}}
+
+📍 <b>สถานที่:</b> {{or .Request.Location "ไม่ระบุ"}}
+📅 <b>เวลา:</b> {{.Time}}
+
+#เปิดงานใหม่`},
+			models.EventRequestSignedOff: {Body: `{{if eq .Request.SignOff "accepted"}}⭐ <b>ผู้แจ้งยืนยันงานซ่อม</b>{{else if eq .Request.SignOff "disputed"}}⚠️ <b>ผู้แจ้งโต้แย้งงานซ่อม</b>{{else}}🔒 <b>ปิดงานซ่อมอัตโนมัติ</b>{{end}}
+
+📋 <b>งาน:</b> {{.Request.Title}}
+🔧 <b>ช่าง:</b> {{with .Technician}}{{.FullName}}{{else}}-{{end}}
+{{if eq .Request.SignOff "accepted"}}⭐ <b>คะแนน:</b> {{.Request.Rating}}/5
+💬 <b>ความคิดเห็น:</b> {{or .Request.RatingComment "-"}}{{else if eq .Request.SignOff "disputed"}}👤 <b>ผู้แจ้ง:</b> {{.Actor.FullName}}
+📝 <b>เหตุผล:</b>
+{{.Reason}}{{else}}ผู้แจ้งไม่ได้ยืนยันงานภายในเวลาที่กำหนด{{end}}
+
+📅 <b>เวลา:</b> {{.Time}}
+
+#ยืนยันงาน`},
+			models.EventRequestRejected: {Body: `❌ <b>ปฏิเสธงานซ่อม</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+👤 <b>ผู้ปฏิเสธ:</b> {{.Actor.FullName}}
+
+📝 <b>เหตุผล:</b>
+{{.Reason}}
+
+📅 <b>เวลา:</b> {{.Time}}
+
+#ปฏิเสธ #ยกเลิก`},
+			models.EventRequestApproval: {Body: `{{if .Approval.Approved}}✅ <b>อนุมัติงานซ่อม</b>{{else}}❌ <b>ไม่อนุมัติงานซ่อม</b>{{end}}
+
+📋 <b>งาน:</b> {{.Request.Title}}
+👤 <b>ผู้แจ้ง:</b> {{.Request.Requester.FullName}}
+🧑‍💼 <b>ผู้อนุมัติ:</b> {{.Actor.FullName}}
+
+🔄 <b>สถานะ:</b>
+{{statusEmoji .OldStatus}} {{statusText .OldStatus}} ➡️ {{statusEmoji .Request.Status}} {{statusText .Request.Status}}
+
+📝 <b>หมายเหตุ:</b> {{or .Approval.Note "-"}}
+📅 <b>เวลา:</b> {{formatTime .Approval.CreatedAt}}
+
+{{if .Approval.Approved}}#อนุมัติ{{else}}#ไม่อนุมัติ{{end}}`},
+			models.EventCommentAdded: {Body: `💬 <b>ความคิดเห็นใหม่</b>
+
+📋 <b>งาน:</b> {{.Request.Title}}
+👤 <b>จาก:</b> {{.Actor.FullName}}
+📨 <b>ถึง:</b> {{with .Recipient}}{{.FullName}}{{else}}ยังไม่ได้มอบหมาย{{end}}
+
+📝 <b>ข้อความ:</b>
+{{truncate 200 .Comment.Content}}
+
+📅 <b>เวลา:</b> {{formatTime .Comment.CreatedAt}}
+
+#ความคิดเห็น`},
+		},
+		LocaleEnglish: {
+			models.EventRequestCreated: {Body: `🔧 <b>New repair request</b>
+
+{{priorityEmoji .Request.Priority}} <b>{{.Request.Title}}</b> {{statusEmoji .Request.Status}}
+
+📋 <b>Details:</b>
+• Title: {{.Request.Title}}
+• Description: {{truncate 100 .Request.Description}}
+• Location: {{or .Request.Location "Not specified"}}
+• Priority: {{priorityText .Request.Priority}} {{priorityEmoji .Request.Priority}}
+• Status: {{statusText .Request.Status}} {{statusEmoji .Request.Status}}
+
+👤 <b>Requested by:</b> {{.Actor.FullName}} ({{.Actor.Username}})
+🕐 <b>Time:</b> {{formatTime .Request.CreatedAt}}
+
+#repair #new #{{lower .Request.Priority}}`},
+			models.EventRequestStatusChanged: {Body: `🔄 <b>Repair status changed</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+
+🔄 <b>Status:</b>
+{{statusEmoji .OldStatus}} {{statusText .OldStatus}} ➡️ {{statusEmoji .Request.Status}} {{statusText .Request.Status}}
+
+👤 <b>Technician:</b> {{with .Technician}}{{.FullName}}{{else}}Not assigned{{end}}
+🕐 <b>Time:</b> {{.Time}}
+
+#status #{{lower .Request.Status}}`},
+			models.EventRequestAssigned: {Body: `👷‍♂️ <b>Repair assigned</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+🔧 <b>Technician:</b> {{.Technician.FullName}}
+⚡ <b>Priority:</b> {{priorityText .Request.Priority}} {{priorityEmoji .Request.Priority}}
+
+📍 <b>Location:</b> {{or .Request.Location "Not specified"}}
+📅 <b>Assigned at:</b> {{.Time}}
+
+#assigned #{{.Technician.Username}}`},
+			models.EventRequestCompleted: {Body: `✅ <b>Repair completed</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+🔧 <b>Technician:</b> {{.Technician.FullName}}
+⏱️ <b>Duration:</b> {{duration .Request.CreatedAt .Request.CompletedAt}}
+💰 <b>Cost:</b> {{money .Request.Cost}}
+
+📅 <b>Completed at:</b> {{formatTime .Request.CompletedAt}}
+
+#completed`},
+			models.EventRequestReopened: {Body: `🔁 <b>Repair request reopened</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+👤 <b>Requested by:</b> {{.Actor.FullName}}
+🔧 <b>Last technician:</b> {{with .Technician}}{{.FullName}}{{else}}none{{end}}
+🔢 <b>Times reopened:</b> {{.Request.ReopenCount}}
+
+📝 <b>Reason:</b>
+{{.Reason}}
+
+📍 <b>Location:</b> {{or .Request.Location "Not specified"}}
+📅 <b>Time:</b> {{.Time}}
+
+#reopened`},
+			models.EventRequestSignedOff: {Body: `{{if eq .Request.SignOff "accepted"}}⭐ <b>Repair accepted by requester</b>{{else if eq .Request.SignOff "disputed"}}⚠️ <b>Repair disputed by requester</b>{{else}}🔒 <b>Repair closed automatically</b>{{end}}
+
+📋 <b>Job:</b> {{.Request.Title}}
+🔧 <b>Technician:</b> {{with .Technician}}{{.FullName}}{{else}}-{{end}}
+{{if eq .Request.SignOff "accepted"}}⭐ <b>Rating:</b> {{.Request.Rating}}/5
+💬 <b>Comment:</b> {{or .Request.RatingComment "-"}}{{else if eq .Request.SignOff "disputed"}}👤 <b>Requested by:</b> {{.Actor.FullName}}
+📝 <b>Reason:</b>
+{{.Reason}}{{else}}The requester did not confirm the job in time{{end}}
+
+📅 <b>Time:</b> {{.Time}}
+
+#signoff`},
+			models.EventRequestRejected: {Body: `❌ <b>Repair request rejected</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+👤 <b>Rejected by:</b> {{.Actor.FullName}}
+
+📝 <b>Reason:</b>
+{{.Reason}}
+
+📅 <b>Time:</b> {{.Time}}
+
+#rejected`},
+			models.EventRequestApproval: {Body: `{{if .Approval.Approved}}✅ <b>Repair approved</b>{{else}}❌ <b>Repair not approved</b>{{end}}
+
+📋 <b>Job:</b> {{.Request.Title}}
+👤 <b>Requested by:</b> {{.Request.Requester.FullName}}
+🧑‍💼 <b>Approver:</b> {{.Actor.FullName}}
+
+🔄 <b>Status:</b>
+{{statusEmoji .OldStatus}} {{statusText .OldStatus}} ➡️ {{statusEmoji .Request.Status}} {{statusText .Request.Status}}
+
+📝 <b>Note:</b> {{or .Approval.Note "-"}}
+📅 <b>Time:</b> {{formatTime .Approval.CreatedAt}}
+
+{{if .Approval.Approved}}#approved{{else}}#not_approved{{end}}`},
+			models.EventCommentAdded: {Body: `💬 <b>New comment</b>
+
+📋 <b>Job:</b> {{.Request.Title}}
+👤 <b>From:</b> {{.Actor.FullName}}
+📨 <b>To:</b> {{with .Recipient}}{{.FullName}}{{else}}Not assigned{{end}}
+
+📝 <b>Message:</b>
+{{truncate 200 .Comment.Content}}
+
+📅 <b>Time:</b> {{formatTime .Comment.CreatedAt}}
+
+#comment`},
+		},
+	},
+	"email": {
+		LocaleThai: {
+			models.EventRequestCreated: {
+				Subject: `[{{.SiteName}}] แจ้งซ่อมใหม่ #{{.Request.ID}}: {{.Request.Title}}`,
+				Body: `มีการแจ้งซ่อมใหม่จาก {{.Actor.FullName}}
+
+{{.Request.Description}}
+` + emailTextFooterTH,
+				HTML: `<p>มีการแจ้งซ่อมใหม่จาก <b>{{.Actor.FullName}}</b></p>
+<p>{{.Request.Description}}</p>`,
+			},
+			models.EventRequestStatusChanged: {
+				Subject: `[{{.SiteName}}] งาน #{{.Request.ID}} เปลี่ยนสถานะเป็น {{statusText .Request.Status}}`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+งานแจ้งซ่อมของคุณเปลี่ยนสถานะจาก {{statusText .OldStatus}} เป็น {{statusText .Request.Status}}
+{{with .Technician}}ช่างผู้รับผิดชอบ: {{.FullName}}
+{{end}}` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>งานแจ้งซ่อมของคุณเปลี่ยนสถานะจาก <b>{{statusText .OldStatus}}</b> เป็น <b>{{statusText .Request.Status}}</b></p>
+{{with .Technician}}<p>ช่างผู้รับผิดชอบ: {{.FullName}}</p>{{end}}`,
+			},
+			models.EventRequestAssigned: {
+				Subject: `[{{.SiteName}}] มอบหมายงาน #{{.Request.ID}}: {{.Request.Title}}`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+คุณได้รับมอบหมายงานแจ้งซ่อมจาก {{.Request.Requester.FullName}}
+
+{{.Request.Description}}
+` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>คุณได้รับมอบหมายงานแจ้งซ่อมจาก <b>{{.Request.Requester.FullName}}</b></p>
+<p>{{.Request.Description}}</p>`,
+			},
+			models.EventRequestCompleted: {
+				Subject: `[{{.SiteName}}] งาน #{{.Request.ID}} เสร็จสิ้นแล้ว`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+งานแจ้งซ่อมของคุณดำเนินการเสร็จสิ้นแล้ว
+{{with .Technician}}ช่างผู้รับผิดชอบ: {{.FullName}}
+{{end}}` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>งานแจ้งซ่อมของคุณดำเนินการ<b>เสร็จสิ้น</b>แล้ว</p>
+{{with .Technician}}<p>ช่างผู้รับผิดชอบ: {{.FullName}}</p>{{end}}`,
+			},
+			models.EventRequestReopened: {
+				Subject: `[{{.SiteName}}] งาน #{{.Request.ID}} ถูกเปิดอีกครั้ง`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+{{.Actor.FullName}} เปิดงานแจ้งซ่อมที่คุณเคยดำเนินการเสร็จแล้วอีกครั้ง (ครั้งที่ {{.Request.ReopenCount}})
+เหตุผล: {{.Reason}}
+` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>{{.Actor.FullName}} <b>เปิดงานแจ้งซ่อม</b>ที่คุณเคยดำเนินการเสร็จแล้วอีกครั้ง (ครั้งที่ {{.Request.ReopenCount}})</p>
+<p>เหตุผล: {{.Reason}}</p>`,
+			},
+			models.EventRequestSignedOff: {
+				Subject: `[{{.SiteName}}] งาน #{{.Request.ID}} {{if eq .Request.SignOff "accepted"}}ได้รับการยืนยันแล้ว{{else if eq .Request.SignOff "disputed"}}ถูกโต้แย้ง{{else}}ปิดอัตโนมัติแล้ว{{end}}`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+{{if eq .Request.SignOff "accepted"}}{{.Actor.FullName}} ยืนยันงานซ่อมของคุณแล้ว ให้คะแนน {{.Request.Rating}}/5
+{{with .Request.RatingComment}}ความคิดเห็น: {{.}}
+{{end}}{{else if eq .Request.SignOff "disputed"}}{{.Actor.FullName}} โต้แย้งงานซ่อมของคุณ
+เหตุผล: {{.Reason}}
+{{else}}งานซ่อมของคุณถูกปิดอัตโนมัติ เนื่องจากผู้แจ้งไม่ได้ยืนยันภายในเวลาที่กำหนด
+{{end}}` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+{{if eq .Request.SignOff "accepted"}}<p>{{.Actor.FullName}} <b>ยืนยัน</b>งานซ่อมของคุณแล้ว ให้คะแนน <b>{{.Request.Rating}}/5</b></p>
+{{with .Request.RatingComment}}<p>ความคิดเห็น: {{.}}</p>{{end}}{{else if eq .Request.SignOff "disputed"}}<p>{{.Actor.FullName}} <b>โต้แย้ง</b>งานซ่อมของคุณ</p>
+<p>เหตุผล: {{.Reason}}</p>{{else}}<p>งานซ่อมของคุณถูก<b>ปิดอัตโนมัติ</b> เนื่องจากผู้แจ้งไม่ได้ยืนยันภายในเวลาที่กำหนด</p>{{end}}`,
+			},
+			models.EventRequestRejected: {
+				Subject: `[{{.SiteName}}] งาน #{{.Request.ID}} ถูกปฏิเสธ`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+งานแจ้งซ่อมของคุณถูกปฏิเสธโดย {{.Actor.FullName}}
+เหตุผล: {{.Reason}}
+` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>งานแจ้งซ่อมของคุณถูก<b>ปฏิเสธ</b>โดย {{.Actor.FullName}}</p>
+<p>เหตุผล: {{.Reason}}</p>`,
+			},
+			models.EventRequestApproval: {
+				Subject: `[{{.SiteName}}] ผลการอนุมัติงาน #{{.Request.ID}}: {{if .Approval.Approved}}อนุมัติ{{else}}ไม่อนุมัติ{{end}}`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+งานแจ้งซ่อมของคุณ{{if .Approval.Approved}}ได้รับการอนุมัติ{{else}}ไม่ได้รับการอนุมัติ{{end}}โดย {{.Actor.FullName}}
+{{with .Approval.Note}}หมายเหตุ: {{.}}
+{{end}}` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p>งานแจ้งซ่อมของคุณ<b>{{if .Approval.Approved}}ได้รับการอนุมัติ{{else}}ไม่ได้รับการอนุมัติ{{end}}</b>โดย {{.Actor.FullName}}</p>
+{{with .Approval.Note}}<p>หมายเหตุ: {{.}}</p>{{end}}`,
+			},
+			models.EventCommentAdded: {
+				Subject: `[{{.SiteName}}] ความคิดเห็นใหม่ในงาน #{{.Request.ID}}`,
+				Body: `เรียน {{.Recipient.FullName}}
+
+{{.Actor.FullName}} แสดงความคิดเห็น:
+{{.Comment.Content}}
+` + emailTextFooterTH,
+				HTML: `<p>เรียน {{.Recipient.FullName}}</p>
+<p><b>{{.Actor.FullName}}</b> แสดงความคิดเห็น:</p>
+<blockquote style="border-left: 3px solid #ccc; margin: 0; padding-left: 12px;">{{.Comment.Content}}</blockquote>`,
+			},
+		},
+		LocaleEnglish: {
+			models.EventRequestCreated: {
+				Subject: `[{{.SiteName}}] New repair request #{{.Request.ID}}: {{.Request.Title}}`,
+				Body: `{{.Actor.FullName}} submitted a new repair request
+
+{{.Request.Description}}
+` + emailTextFooterEN,
+				HTML: `<p><b>{{.Actor.FullName}}</b> submitted a new repair request</p>
+<p>{{.Request.Description}}</p>`,
+			},
+			models.EventRequestStatusChanged: {
+				Subject: `[{{.SiteName}}] Request #{{.Request.ID}} is now {{statusText .Request.Status}}`,
+				Body: `Dear {{.Recipient.FullName}},
+
+Your repair request changed from {{statusText .OldStatus}} to {{statusText .Request.Status}}
+{{with .Technician}}Technician: {{.FullName}}
+{{end}}` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>Your repair request changed from <b>{{statusText .OldStatus}}</b> to <b>{{statusText .Request.Status}}</b></p>
+{{with .Technician}}<p>Technician: {{.FullName}}</p>{{end}}`,
+			},
+			models.EventRequestAssigned: {
+				Subject: `[{{.SiteName}}] Assigned to you #{{.Request.ID}}: {{.Request.Title}}`,
+				Body: `Dear {{.Recipient.FullName}},
+
+You have been assigned a repair request from {{.Request.Requester.FullName}}
+
+{{.Request.Description}}
+` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>You have been assigned a repair request from <b>{{.Request.Requester.FullName}}</b></p>
+<p>{{.Request.Description}}</p>`,
+			},
+			models.EventRequestCompleted: {
+				Subject: `[{{.SiteName}}] Request #{{.Request.ID}} is complete`,
+				Body: `Dear {{.Recipient.FullName}},
+
+Your repair request has been completed
+{{with .Technician}}Technician: {{.FullName}}
+{{end}}` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>Your repair request has been <b>completed</b></p>
+{{with .Technician}}<p>Technician: {{.FullName}}</p>{{end}}`,
+			},
+			models.EventRequestReopened: {
+				Subject: `[{{.SiteName}}] Request #{{.Request.ID}} was reopened`,
+				Body: `Dear {{.Recipient.FullName}},
+
+{{.Actor.FullName}} reopened a repair request you completed (reopened {{.Request.ReopenCount}} time(s))
+Reason: {{.Reason}}
+` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>{{.Actor.FullName}} <b>reopened</b> a repair request you completed (reopened {{.Request.ReopenCount}} time(s))</p>
+<p>Reason: {{.Reason}}</p>`,
+			},
+			models.EventRequestSignedOff: {
+				Subject: `[{{.SiteName}}] Request #{{.Request.ID}} {{if eq .Request.SignOff "accepted"}}was accepted{{else if eq .Request.SignOff "disputed"}}was disputed{{else}}closed automatically{{end}}`,
+				Body: `Dear {{.Recipient.FullName}},
+
+{{if eq .Request.SignOff "accepted"}}{{.Actor.FullName}} accepted your repair with a rating of {{.Request.Rating}}/5
+{{with .Request.RatingComment}}Comment: {{.}}
+{{end}}{{else if eq .Request.SignOff "disputed"}}{{.Actor.FullName}} disputed your repair
+Reason: {{.Reason}}
+{{else}}Your repair was closed automatically because the requester did not confirm it in time
+{{end}}` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+{{if eq .Request.SignOff "accepted"}}<p>{{.Actor.FullName}} <b>accepted</b> your repair with a rating of <b>{{.Request.Rating}}/5</b></p>
+{{with .Request.RatingComment}}<p>Comment: {{.}}</p>{{end}}{{else if eq .Request.SignOff "disputed"}}<p>{{.Actor.FullName}} <b>disputed</b> your repair</p>
+<p>Reason: {{.Reason}}</p>{{else}}<p>Your repair was <b>closed automatically</b> because the requester did not confirm it in time</p>{{end}}`,
+			},
+			models.EventRequestRejected: {
+				Subject: `[{{.SiteName}}] Request #{{.Request.ID}} was rejected`,
+				Body: `Dear {{.Recipient.FullName}},
+
+Your repair request was rejected by {{.Actor.FullName}}
+Reason: {{.Reason}}
+` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>Your repair request was <b>rejected</b> by {{.Actor.FullName}}</p>
+<p>Reason: {{.Reason}}</p>`,
+			},
+			models.EventRequestApproval: {
+				Subject: `[{{.SiteName}}] Approval for request #{{.Request.ID}}: {{if .Approval.Approved}}approved{{else}}not approved{{end}}`,
+				Body: `Dear {{.Recipient.FullName}},
+
+Your repair request was {{if .Approval.Approved}}approved{{else}}not approved{{end}} by {{.Actor.FullName}}
+{{with .Approval.Note}}Note: {{.}}
+{{end}}` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p>Your repair request was <b>{{if .Approval.Approved}}approved{{else}}not approved{{end}}</b> by {{.Actor.FullName}}</p>
+{{with .Approval.Note}}<p>Note: {{.}}</p>{{end}}`,
+			},
+			models.EventCommentAdded: {
+				Subject: `[{{.SiteName}}] New comment on request #{{.Request.ID}}`,
+				Body: `Dear {{.Recipient.FullName}},
+
+{{.Actor.FullName}} commented:
+{{.Comment.Content}}
+` + emailTextFooterEN,
+				HTML: `<p>Dear {{.Recipient.FullName}},</p>
+<p><b>{{.Actor.FullName}}</b> commented:</p>
+<blockquote style="border-left: 3px solid #ccc; margin: 0; padding-left: 12px;">{{.Comment.Content}}</blockquote>`,
+			},
+		},
+	},
+}
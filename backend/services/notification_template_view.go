@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"text/template/parse"
+	"time"
+
+	"repair-system/models"
+)
+
+// Templates render against these views rather than the models, so an edited template
+// can only print what a notification is meant to show. Hashes, phone numbers and
+// Telegram IDs never reach a template.
+
+type NotificationUserView struct {
+	ID       uint
+	Username string
+	FullName string
+	Role     models.UserRole
+}
+
+type NotificationCategoryView struct {
+	ID   uint
+	Name string
+}
+
+type NotificationRequestView struct {
+	ID              uint
+	CreatedAt       time.Time
+	Title           string
+	Description     string
+	Location        string
+	Category        NotificationCategoryView
+	Requester       NotificationUserView
+	Technician      *NotificationUserView
+	Status          models.RepairStatus
+	Priority        models.RepairPriority
+	Cost            float64
+	CompletedAt     *time.Time
+	RejectionReason string
+	ReopenCount     int
+	ReopenReason    string
+	SignOff         models.SignOffStatus
+	Rating          *int
+	RatingComment   string
+	DisputeReason   string
+}
+
+type NotificationCommentView struct {
+	ID        uint
+	CreatedAt time.Time
+	Content   string
+	Author    NotificationUserView
+}
+
+type NotificationApprovalView struct {
+	CreatedAt time.Time
+	Approved  bool
+	Note      string
+	Approver  NotificationUserView
+}
+
+// notificationView is NotificationData as a template sees it
+type notificationView struct {
+	SiteName   string
+	Time       string
+	Locale     string
+	Request    *NotificationRequestView
+	Recipient  *NotificationUserView
+	Technician *NotificationUserView
+	Actor      *NotificationUserView
+	OldStatus  string
+	Reason     string
+	Comment    *NotificationCommentView
+	Approval   *NotificationApprovalView
+}
+
+func newNotificationView(data NotificationData) notificationView {
+	view := notificationView{
+		SiteName:   data.SiteName,
+		Time:       data.Time,
+		Locale:     data.Locale,
+		Recipient:  userView(data.Recipient),
+		Technician: userView(data.Technician),
+		Actor:      userView(data.Actor),
+		OldStatus:  data.OldStatus,
+		Reason:     data.Reason,
+	}
+	if r := data.Request; r != nil {
+		view.Request = &NotificationRequestView{
+			ID:              r.ID,
+			CreatedAt:       r.CreatedAt,
+			Title:           r.Title,
+			Description:     r.Description,
+			Location:        r.Location,
+			Category:        NotificationCategoryView{ID: r.Category.ID, Name: r.Category.Name},
+			Requester:       *userView(&r.Requester),
+			Technician:      userView(r.Technician),
+			Status:          r.Status,
+			Priority:        r.Priority,
+			Cost:            r.Cost,
+			CompletedAt:     r.CompletedAt,
+			RejectionReason: r.RejectionReason,
+			ReopenCount:     r.ReopenCount,
+			ReopenReason:    r.ReopenReason,
+			SignOff:         r.SignOff,
+			Rating:          r.Rating,
+			RatingComment:   r.RatingComment,
+			DisputeReason:   r.DisputeReason,
+		}
+	}
+	if c := data.Comment; c != nil {
+		view.Comment = &NotificationCommentView{ID: c.ID, CreatedAt: c.CreatedAt, Content: c.Content, Author: *userView(&c.User)}
+	}
+	if a := data.Approval; a != nil {
+		view.Approval = &NotificationApprovalView{CreatedAt: a.CreatedAt, Approved: a.Approved, Note: a.Note, Approver: *userView(&a.Approver)}
+	}
+	return view
+}
+
+func userView(user *models.User) *NotificationUserView {
+	if user == nil {
+		return nil
+	}
+	return &NotificationUserView{ID: user.ID, Username: user.Username, FullName: user.FullName, Role: user.Role}
+}
+
+// notificationTemplateFields holds every field name a template may use
+var notificationTemplateFields = collectFieldNames(reflect.TypeOf(notificationView{}), map[string]bool{})
+
+func collectFieldNames(t reflect.Type, names map[string]bool) map[string]bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() == "time" {
+		return names
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		names[field.Name] = true
+		collectFieldNames(field.Type, names)
+	}
+	return names
+}
+
+// checkTemplateFields rejects a template that refers to a field no view has. Execution
+// would fail on such a field anyway, but only when its branch runs; this catches it
+// when the template is saved.
+func checkTemplateFields(node parse.Node) error {
+	var idents []string
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateFields(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.ActionNode:
+		return checkTemplateFields(n.Pipe)
+	case *parse.IfNode:
+		return checkBranchFields(&n.BranchNode)
+	case *parse.RangeNode:
+		return checkBranchFields(&n.BranchNode)
+	case *parse.WithNode:
+		return checkBranchFields(&n.BranchNode)
+	case *parse.TemplateNode:
+		return checkTemplateFields(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		for _, cmd := range n.Cmds {
+			if err := checkTemplateFields(cmd); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			if err := checkTemplateFields(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parse.FieldNode:
+		idents = n.Ident
+	case *parse.ChainNode:
+		if err := checkTemplateFields(n.Node); err != nil {
+			return err
+		}
+		idents = n.Field
+	case *parse.VariableNode:
+		idents = n.Ident[1:] // The first identifier is the variable itself
+	}
+
+	for _, ident := range idents {
+		if !notificationTemplateFields[ident] {
+			return fmt.Errorf("unknown field %s", ident)
+		}
+	}
+	return nil
+}
+
+func checkBranchFields(n *parse.BranchNode) error {
+	if err := checkTemplateFields(n.Pipe); err != nil {
+		return err
+	}
+	if err := checkTemplateFields(n.List); err != nil {
+		return err
+	}
+	return checkTemplateFields(n.ElseList)
+}
@@ -0,0 +1,321 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"repair-system/models"
+)
+
+// Audiences are who a channel reaches for an event
+const (
+	NotificationAudienceGroup       = "group"       // The Telegram group chat
+	NotificationAudienceAdmin       = "admin"       // The admin email address
+	NotificationAudienceRequester   = "requester"   // The person who opened the request
+	NotificationAudienceTechnician  = "technician"  // The assigned technician
+	NotificationAudienceSubscribers = "subscribers" // Webhooks subscribed to the event
+)
+
+var ErrUnknownNotificationRule = errors.New("unknown notification rule")
+
+// Notifier delivers repair request events over one channel, such as a Telegram group
+type Notifier interface {
+	Channel() string
+	IsEnabled() bool
+	// Audiences returns who the channel notifies for the event, or nothing if it stays silent
+	Audiences(event string) []string
+	NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error
+	NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error
+	NotifyAssignment(request *models.RepairRequest, technician *models.User) error
+	NotifyCompletion(request *models.RepairRequest, technician *models.User) error
+	NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error
+}
+
+// CommentNotifier is implemented by notifiers that also announce new comments
+type CommentNotifier interface {
+	NotifyNewComment(request *models.RepairRequest, comment *models.Comment, author *models.User, recipient *models.User) error
+}
+
+// ApprovalNotifier is implemented by notifiers that also announce approval decisions
+type ApprovalNotifier interface {
+	NotifyApproval(request *models.RepairRequest, approval *models.Approval, oldStatus string) error
+}
+
+// ReopenNotifier is implemented by notifiers that also announce reopened requests
+type ReopenNotifier interface {
+	NotifyReopen(request *models.RepairRequest, technician *models.User, reason string) error
+}
+
+// SignOffNotifier is implemented by notifiers that also announce how the requester
+// signed off a completed job. requester is nil when the job closed itself.
+type SignOffNotifier interface {
+	NotifySignOff(request *models.RepairRequest, technician *models.User, requester *models.User) error
+}
+
+// Notification is a loaded event ready to be handed to a notifier
+type Notification struct {
+	Event     string
+	Request   *models.RepairRequest
+	Actor     *models.User
+	OldStatus string
+	Comment   *models.Comment
+	Approval  *models.Approval
+}
+
+// NotificationEvents lists every event a notifier can receive, in display order
+var NotificationEvents = []string{
+	models.EventRequestCreated,
+	models.EventRequestStatusChanged,
+	models.EventRequestAssigned,
+	models.EventRequestCompleted,
+	models.EventRequestReopened,
+	models.EventRequestSignedOff,
+	models.EventRequestRejected,
+	models.EventRequestApproval,
+	models.EventCommentAdded,
+}
+
+// notificationEventKeys names each event in the per-channel settings,
+// e.g. telegram_notify_new_request
+var notificationEventKeys = map[string]string{
+	models.EventRequestCreated:       "new_request",
+	models.EventRequestStatusChanged: "status_change",
+	models.EventRequestAssigned:      "assignment",
+	models.EventRequestCompleted:     "completion",
+	models.EventRequestReopened:      "reopen",
+	models.EventRequestSignedOff:     "sign_off",
+	models.EventRequestRejected:      "rejection",
+	models.EventRequestApproval:      "approval",
+	models.EventCommentAdded:         "comment",
+}
+
+// NotificationSettingKey returns the setting that switches an event on or off for a channel
+func NotificationSettingKey(channel, event string) string {
+	return channel + "_notify_" + notificationEventKeys[event]
+}
+
+// NotificationRule is one cell of the event × channel × audience matrix
+type NotificationRule struct {
+	Event    string `json:"event"`
+	Channel  string `json:"channel"`
+	Audience string `json:"audience"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// NotificationDispatcher fans events out to every registered notifier
+type NotificationDispatcher struct {
+	settingsService *SettingsService
+	notifiers       []Notifier
+}
+
+func NewNotificationDispatcher(settingsService *SettingsService) *NotificationDispatcher {
+	d := &NotificationDispatcher{settingsService: settingsService}
+	d.Register(NewTelegramServiceWithSettings(settingsService))
+	d.Register(NewTelegramDirectService(settingsService))
+	d.Register(NewEmailService(settingsService))
+	d.Register(NewWebhookService())
+	return d
+}
+
+// Register adds a notifier, replacing any existing one for the same channel
+func (d *NotificationDispatcher) Register(notifier Notifier) {
+	for i, n := range d.notifiers {
+		if n.Channel() == notifier.Channel() {
+			d.notifiers[i] = notifier
+			return
+		}
+	}
+	d.notifiers = append(d.notifiers, notifier)
+}
+
+// Notifiers returns the registered notifiers in registration order
+func (d *NotificationDispatcher) Notifiers() []Notifier {
+	return append([]Notifier(nil), d.notifiers...)
+}
+
+// Channels returns the channels that are switched on and should receive the event
+func (d *NotificationDispatcher) Channels(event string) []string {
+	channels := []string{}
+	for _, n := range d.notifiers {
+		if n.IsEnabled() && d.supports(n, event) && d.IsEventEnabled(n.Channel(), event) {
+			channels = append(channels, n.Channel())
+		}
+	}
+	return channels
+}
+
+// IsEventEnabled reports whether the channel sends the event to at least one audience
+func (d *NotificationDispatcher) IsEventEnabled(channel, event string) bool {
+	notifier := d.notifier(channel)
+	if notifier == nil {
+		return false
+	}
+	for _, audience := range notifier.Audiences(event) {
+		if d.IsAudienceEnabled(channel, event, audience) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAudienceEnabled reports whether the channel sends the event to the audience.
+// Rules are on unless an admin has switched them off.
+func (d *NotificationDispatcher) IsAudienceEnabled(channel, event, audience string) bool {
+	key, ok := d.ruleKey(channel, event, audience)
+	if !ok {
+		return false
+	}
+	value := d.settingsService.GetSettingWithDefault(key, "true")
+	return strings.EqualFold(value, "true")
+}
+
+// Rules returns the whole matrix, one rule per event, channel and audience the channel reaches
+func (d *NotificationDispatcher) Rules() []NotificationRule {
+	rules := []NotificationRule{}
+	for _, event := range NotificationEvents {
+		for _, n := range d.notifiers {
+			if !d.supports(n, event) {
+				continue
+			}
+			for _, audience := range n.Audiences(event) {
+				rules = append(rules, NotificationRule{
+					Event:    event,
+					Channel:  n.Channel(),
+					Audience: audience,
+					Enabled:  d.IsAudienceEnabled(n.Channel(), event, audience),
+				})
+			}
+		}
+	}
+	return rules
+}
+
+// SetRule switches one cell of the matrix on or off
+func (d *NotificationDispatcher) SetRule(rule NotificationRule) error {
+	key, ok := d.ruleKey(rule.Channel, rule.Event, rule.Audience)
+	if !ok {
+		return fmt.Errorf("%w: %s to %s over %s", ErrUnknownNotificationRule, rule.Event, rule.Audience, rule.Channel)
+	}
+	return d.settingsService.SetBoolSetting(key, rule.Enabled)
+}
+
+// ruleKey returns the setting behind a rule. A channel that reaches a single audience
+// for the event keeps the plain NotificationSettingKey, so telegram_notify_new_request
+// and friends still apply; otherwise each audience gets its own suffixed key.
+func (d *NotificationDispatcher) ruleKey(channel, event, audience string) (string, bool) {
+	notifier := d.notifier(channel)
+	if notifier == nil || !d.supports(notifier, event) {
+		return "", false
+	}
+	audiences := notifier.Audiences(event)
+	for _, a := range audiences {
+		if a != audience {
+			continue
+		}
+		if len(audiences) == 1 {
+			return NotificationSettingKey(channel, event), true
+		}
+		return NotificationSettingKey(channel, event) + "_" + audience, true
+	}
+	return "", false
+}
+
+// Dispatch delivers the notification over a single channel
+func (d *NotificationDispatcher) Dispatch(channel string, notification *Notification) error {
+	notifier := d.notifier(channel)
+	if notifier == nil {
+		return fmt.Errorf("unknown notification channel %s", channel)
+	}
+
+	// Rules may have been switched off while the message waited in the outbox
+	if !d.IsEventEnabled(channel, notification.Event) {
+		return nil
+	}
+
+	request := notification.Request
+	switch notification.Event {
+	case models.EventRequestCreated:
+		return notifier.NotifyNewRepairRequest(request, &request.Requester)
+	case models.EventRequestStatusChanged:
+		return notifier.NotifyStatusChange(request, notification.OldStatus, request.Technician)
+	case models.EventRequestAssigned:
+		if request.Technician == nil {
+			return nil
+		}
+		return notifier.NotifyAssignment(request, request.Technician)
+	case models.EventRequestCompleted:
+		if request.Technician == nil {
+			return nil
+		}
+		return notifier.NotifyCompletion(request, request.Technician)
+	case models.EventRequestReopened:
+		if n, ok := notifier.(ReopenNotifier); ok {
+			return n.NotifyReopen(request, request.Technician, request.ReopenReason)
+		}
+		return nil
+	case models.EventRequestSignedOff:
+		// The job was completed again before the message went out
+		if request.SignOff == "" {
+			return nil
+		}
+		if n, ok := notifier.(SignOffNotifier); ok {
+			return n.NotifySignOff(request, request.Technician, notification.Actor)
+		}
+		return nil
+	case models.EventRequestRejected:
+		if notification.Actor == nil {
+			return fmt.Errorf("rejection of repair request %d has no actor", request.ID)
+		}
+		return notifier.NotifyRejection(request, request.RejectionReason, notification.Actor)
+	case models.EventRequestApproval:
+		if n, ok := notifier.(ApprovalNotifier); ok {
+			return n.NotifyApproval(request, notification.Approval, notification.OldStatus)
+		}
+		return nil
+	case models.EventCommentAdded:
+		if n, ok := notifier.(CommentNotifier); ok {
+			recipient, audience := &request.Requester, NotificationAudienceRequester
+			if notification.Comment.UserID == request.RequesterID {
+				recipient, audience = request.Technician, NotificationAudienceTechnician
+			}
+			if !d.IsAudienceEnabled(channel, notification.Event, audience) {
+				return nil
+			}
+			return n.NotifyNewComment(request, notification.Comment, &notification.Comment.User, recipient)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown event %s", notification.Event)
+	}
+}
+
+func (d *NotificationDispatcher) notifier(channel string) Notifier {
+	for _, n := range d.notifiers {
+		if n.Channel() == channel {
+			return n
+		}
+	}
+	return nil
+}
+
+// supports reports whether the notifier has a method for the event
+func (d *NotificationDispatcher) supports(notifier Notifier, event string) bool {
+	switch event {
+	case models.EventRequestApproval:
+		_, ok := notifier.(ApprovalNotifier)
+		return ok
+	case models.EventRequestReopened:
+		_, ok := notifier.(ReopenNotifier)
+		return ok
+	case models.EventRequestSignedOff:
+		_, ok := notifier.(SignOffNotifier)
+		return ok
+	case models.EventCommentAdded:
+		_, ok := notifier.(CommentNotifier)
+		return ok
+	default:
+		_, ok := notificationEventKeys[event]
+		return ok
+	}
+}
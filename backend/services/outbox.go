@@ -0,0 +1,235 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBatchSize   = 20
+	outboxMaxAttempts = 8
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// errNotificationObsolete means a record the message is about was deleted before it went out.
+// Retrying cannot bring it back, so the message is cancelled.
+var errNotificationObsolete = errors.New("notification subject was deleted")
+
+// ErrNotReplayable is returned when replaying a message that has not failed
+var ErrNotReplayable = errors.New("only dead or cancelled notifications can be replayed")
+
+// RetryAfterError is implemented by delivery errors that say when to try again
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+type OutboxService struct {
+	dispatcher *NotificationDispatcher
+}
+
+func NewOutboxService(settingsService *SettingsService) *OutboxService {
+	return &OutboxService{
+		dispatcher: NewNotificationDispatcher(settingsService),
+	}
+}
+
+// Enqueue stores one message per channel that wants the event using tx, so nothing is
+// delivered unless the change commits
+func (s *OutboxService) Enqueue(tx *gorm.DB, event string, payload models.NotificationPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range s.dispatcher.Channels(event) {
+		message := models.OutboxMessage{
+			Event:         event,
+			Channel:       channel,
+			Payload:       string(data),
+			Status:        models.OutboxPending,
+			NextAttemptAt: time.Now(),
+		}
+		if err := tx.Create(&message).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start drains the outbox every interval in a background goroutine
+func (s *OutboxService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.ProcessDue(); err != nil {
+				log.Printf("Warning: Failed to process notification outbox: %v", err)
+			}
+		}
+	}()
+}
+
+// ProcessDue delivers the pending messages whose next attempt is due
+func (s *OutboxService) ProcessDue() error {
+	var messages []models.OutboxMessage
+	err := config.DB.Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, time.Now()).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(outboxBatchSize).
+		Find(&messages).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range messages {
+		s.deliver(&messages[i])
+	}
+	return nil
+}
+
+// List returns outbox messages, newest first, optionally filtered by status and channel
+func (s *OutboxService) List(status models.OutboxStatus, channel string, limit int) ([]models.OutboxMessage, error) {
+	query := config.DB.Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	messages := []models.OutboxMessage{}
+	err := query.Find(&messages).Error
+	return messages, err
+}
+
+// Replay puts a dead or cancelled message back in the queue for immediate delivery.
+// Anything else is still queued or already delivered, and replaying it would send it twice.
+func (s *OutboxService) Replay(id uint) (*models.OutboxMessage, error) {
+	var message models.OutboxMessage
+	if err := config.DB.First(&message, id).Error; err != nil {
+		return nil, err
+	}
+
+	// Checked in the update too, so a double click only queues the message once
+	result := config.DB.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status IN ?", id, []models.OutboxStatus{models.OutboxDead, models.OutboxCancelled}).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"last_error":      "",
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return &message, ErrNotReplayable
+	}
+
+	if err := config.DB.First(&message, id).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (s *OutboxService) deliver(message *models.OutboxMessage) {
+	err := s.dispatch(message)
+	message.Attempts++
+
+	if err == nil {
+		now := time.Now()
+		message.Status = models.OutboxSent
+		message.SentAt = &now
+		message.LastError = ""
+	} else if errors.Is(err, errNotificationObsolete) {
+		message.Status = models.OutboxCancelled
+		message.LastError = err.Error()
+	} else {
+		message.LastError = err.Error()
+		if message.Attempts >= outboxMaxAttempts {
+			message.Status = models.OutboxDead
+			log.Printf("Warning: Notification %d (%s via %s) dead-lettered after %d attempts: %v", message.ID, message.Event, message.Channel, message.Attempts, err)
+		} else {
+			message.NextAttemptAt = time.Now().Add(retryDelay(message.Attempts, err))
+		}
+	}
+
+	if err := config.DB.Save(message).Error; err != nil {
+		log.Printf("Warning: Failed to update notification %d: %v", message.ID, err)
+	}
+}
+
+// retryDelay doubles the delay with every attempt, but never retries sooner than the
+// remote side asked for
+func retryDelay(attempts int, err error) time.Duration {
+	delay := outboxBaseBackoff << (attempts - 1)
+	if delay > outboxMaxBackoff || delay <= 0 {
+		delay = outboxMaxBackoff
+	}
+
+	var retryErr RetryAfterError
+	if errors.As(err, &retryErr) && retryErr.RetryAfter() > delay {
+		delay = retryErr.RetryAfter()
+	}
+	return delay
+}
+
+// dispatch loads the records named in the payload and hands them to the message's channel
+func (s *OutboxService) dispatch(message *models.OutboxMessage) error {
+	var payload models.NotificationPayload
+	if err := json.Unmarshal([]byte(message.Payload), &payload); err != nil {
+		return fmt.Errorf("invalid payload: %v", err)
+	}
+
+	notification := &Notification{Event: message.Event, OldStatus: payload.OldStatus}
+
+	var request models.RepairRequest
+	if err := config.DB.Unscoped().Preload("Category").Preload("Requester").Preload("Technician").
+		First(&request, payload.RepairRequestID).Error; err != nil {
+		return fmt.Errorf("repair request %d: %w", payload.RepairRequestID, obsoleteIfNotFound(err))
+	}
+	notification.Request = &request
+
+	if payload.ActorID != nil {
+		var actor models.User
+		if err := config.DB.Unscoped().First(&actor, *payload.ActorID).Error; err != nil {
+			return fmt.Errorf("user %d: %v", *payload.ActorID, err)
+		}
+		notification.Actor = &actor
+	}
+
+	if payload.CommentID != 0 {
+		var comment models.Comment
+		if err := config.DB.Preload("User").First(&comment, payload.CommentID).Error; err != nil {
+			return fmt.Errorf("comment %d: %w", payload.CommentID, obsoleteIfNotFound(err))
+		}
+		notification.Comment = &comment
+	}
+
+	if payload.ApprovalID != 0 {
+		var approval models.Approval
+		if err := config.DB.Preload("Approver").First(&approval, payload.ApprovalID).Error; err != nil {
+			return fmt.Errorf("approval %d: %v", payload.ApprovalID, err)
+		}
+		notification.Approval = &approval
+	}
+
+	return s.dispatcher.Dispatch(message.Channel, notification)
+}
+
+// obsoleteIfNotFound turns a missing record into errNotificationObsolete
+func obsoleteIfNotFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errNotificationObsolete
+	}
+	return err
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCompletedCostLocked = errors.New("only admins can change costs of a completed repair request")
+	ErrNotRejected         = errors.New("repair request is not rejected")
+	ErrAlreadyAppealed     = errors.New("repair request has already been appealed")
+	ErrAlreadyResubmitted  = errors.New("repair request has already been resubmitted")
+	ErrNotCompleted        = errors.New("repair request is not completed")
+	ErrReopenWindowClosed  = errors.New("repair request can no longer be reopened")
+	ErrAlreadySignedOff    = errors.New("repair request has already been signed off")
+	ErrInvalidRating       = errors.New("rating must be between 1 and 5")
+)
+
+// autoCloseBatchSize caps how many requests one auto-close pass signs off
+const autoCloseBatchSize = 100
+
+// RepairRequestService applies edits to repair requests. The REST API and the Telegram
+// bot both go through it so they share validation, history and notifications.
+type RepairRequestService struct {
+	settingsService   *SettingsService
+	workflowService   *WorkflowService
+	costService       *CostService
+	historyService    *HistoryService
+	outboxService     *OutboxService
+	assignmentService *AssignmentService
+}
+
+func NewRepairRequestService(settingsService *SettingsService) *RepairRequestService {
+	return &RepairRequestService{
+		settingsService:   settingsService,
+		workflowService:   NewWorkflowService(),
+		costService:       NewCostService(),
+		historyService:    NewHistoryService(),
+		outboxService:     NewOutboxService(settingsService),
+		assignmentService: NewAssignmentService(settingsService),
+	}
+}
+
+// Create stores a new request raised by actor, queues its notification and, when
+// auto-assignment is on, picks a technician. The request is returned with its
+// category and requester loaded.
+func (s *RepairRequestService) Create(request *models.RepairRequest, actor models.User) error {
+	request.RequesterID = actor.ID
+	request.Status = s.initialStatus()
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		if err := s.historyService.RecordChange(tx, request.ID, &actor.ID, HistoryFieldStatus, "", string(request.Status)); err != nil {
+			return err
+		}
+		return s.outboxService.Enqueue(tx, models.EventRequestCreated, models.NotificationPayload{RepairRequestID: request.ID, ActorID: &actor.ID})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Load relationships for the response
+	config.DB.Preload("Category").Preload("Requester").First(request, request.ID)
+
+	// Pick a technician straight away when auto-assignment is on
+	if _, err := s.assignmentService.AutoAssign(request, nil); err != nil {
+		log.Printf("Warning: Failed to auto-assign repair request %d: %v", request.ID, err)
+	}
+	return nil
+}
+
+// initialStatus returns the status a newly created repair request starts in
+func (s *RepairRequestService) initialStatus() models.RepairStatus {
+	if s.settingsService.GetBoolSetting(models.SettingRequireApproval) {
+		return models.StatusAwaitingApproval
+	}
+	return models.StatusPending
+}
+
+// Appeal sends a rejected request back for approval with the requester's reason.
+// Each request can be appealed once; after that the requester can only resubmit.
+func (s *RepairRequestService) Appeal(request *models.RepairRequest, actor models.User, reason string) error {
+	if request.Status != models.StatusRejected {
+		return ErrNotRejected
+	}
+	if request.AppealedAt != nil {
+		return ErrAlreadyAppealed
+	}
+
+	before := *request
+	now := time.Now()
+	request.Status = models.StatusAwaitingApproval
+	request.AppealReason = strings.TrimSpace(reason)
+	request.AppealedAt = &now
+
+	// The appeal is made as the requester, whatever the actor's role
+	if err := s.workflowService.ValidateTransition(before.Status, request, models.RoleRequester); err != nil {
+		*request = before
+		return err
+	}
+	return s.save(request, &before, actor)
+}
+
+// Resubmit raises a new request linked to a rejected one. resubmission carries the
+// edited fields; anything left empty is copied from the original.
+func (s *RepairRequestService) Resubmit(original *models.RepairRequest, resubmission *models.RepairRequest, actor models.User) error {
+	if original.Status != models.StatusRejected {
+		return ErrNotRejected
+	}
+	var count int64
+	if err := config.DB.Model(&models.RepairRequest{}).Where("resubmitted_from_id = ?", original.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrAlreadyResubmitted
+	}
+
+	if resubmission.Title == "" {
+		resubmission.Title = original.Title
+	}
+	if resubmission.Description == "" {
+		resubmission.Description = original.Description
+	}
+	if resubmission.Location == "" {
+		resubmission.Location = original.Location
+	}
+	if resubmission.CategoryID == 0 {
+		resubmission.CategoryID = original.CategoryID
+	}
+	if resubmission.Priority == "" {
+		resubmission.Priority = original.Priority
+	}
+	if resubmission.Images == nil {
+		resubmission.Images = original.Images
+	}
+	resubmission.ResubmittedFromID = &original.ID
+	return s.Create(resubmission, actor)
+}
+
+// ReopenWindow returns how long after completion the requester may reopen a request.
+// Zero means reopening is switched off.
+func (s *RepairRequestService) ReopenWindow() time.Duration {
+	days, err := strconv.Atoi(s.settingsService.GetSettingWithDefault(models.SettingReopenWindowDays, "7"))
+	if err != nil || days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// Reopen puts a completed request back in the queue for its last technician. The
+// completion being undone is kept as a models.Reopening.
+func (s *RepairRequestService) Reopen(request *models.RepairRequest, actor models.User, reason string) error {
+	if request.Status != models.StatusCompleted {
+		return ErrNotCompleted
+	}
+	now := time.Now()
+	if request.CompletedAt == nil || now.After(request.CompletedAt.Add(s.ReopenWindow())) {
+		return ErrReopenWindowClosed
+	}
+
+	before := *request
+	reopening := models.Reopening{
+		RepairRequestID: request.ID,
+		ReopenedByID:    actor.ID,
+		TechnicianID:    request.TechnicianID,
+		CompletedAt:     request.CompletedAt,
+		Reason:          strings.TrimSpace(reason),
+	}
+	request.Status = models.StatusPending
+	request.CompletedAt = nil
+	request.ReopenCount++
+	request.ReopenReason = reopening.Reason
+	request.ReopenedAt = &now
+
+	// The reopen is made as the requester, whatever the actor's role
+	if err := s.workflowService.ValidateTransition(before.Status, request, models.RoleRequester); err != nil {
+		*request = before
+		return err
+	}
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reopening).Error; err != nil {
+			return err
+		}
+		return s.persist(tx, request, &before, actor)
+	})
+}
+
+// Accept signs off a completed job on the requester's behalf with a 1 to 5 rating
+func (s *RepairRequestService) Accept(request *models.RepairRequest, actor models.User, rating int, comment string) error {
+	if rating < 1 || rating > 5 {
+		return ErrInvalidRating
+	}
+	return s.signOff(request, actor, func() {
+		request.SignOff = models.SignOffAccepted
+		request.Rating = &rating
+		request.RatingComment = strings.TrimSpace(comment)
+	})
+}
+
+// Dispute records that the requester does not accept a completed job. The job stays
+// completed; staff follow up, or the requester reopens it.
+func (s *RepairRequestService) Dispute(request *models.RepairRequest, actor models.User, reason string) error {
+	return s.signOff(request, actor, func() {
+		request.SignOff = models.SignOffDisputed
+		request.DisputeReason = strings.TrimSpace(reason)
+	})
+}
+
+func (s *RepairRequestService) signOff(request *models.RepairRequest, actor models.User, apply func()) error {
+	if request.Status != models.StatusCompleted {
+		return ErrNotCompleted
+	}
+	if request.SignOff != "" {
+		return ErrAlreadySignedOff
+	}
+
+	before := *request
+	now := time.Now()
+	apply()
+	request.SignedOffAt = &now
+	return s.save(request, &before, actor)
+}
+
+// SignOffPeriod returns how long a completed job waits for the requester before it
+// closes itself. Zero means jobs wait forever.
+func (s *RepairRequestService) SignOffPeriod() time.Duration {
+	days, err := strconv.Atoi(s.settingsService.GetSettingWithDefault(models.SettingSignOffDays, "7"))
+	if err != nil || days < 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// StartAutoClose signs off unconfirmed jobs every interval in a background goroutine
+func (s *RepairRequestService) StartAutoClose(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.AutoCloseDue(); err != nil {
+				log.Printf("Warning: Failed to auto-close repair requests: %v", err)
+			}
+		}
+	}()
+}
+
+// AutoCloseDue closes the completed jobs whose requester has not answered within the
+// sign-off period. The change is recorded as made by the system.
+func (s *RepairRequestService) AutoCloseDue() error {
+	period := s.SignOffPeriod()
+	if period == 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-period)
+	var requests []models.RepairRequest
+	err := config.DB.Where("status = ? AND (sign_off = '' OR sign_off IS NULL) AND completed_at <= ?", models.StatusCompleted, cutoff).
+		Order("completed_at ASC").
+		Limit(autoCloseBatchSize).
+		Find(&requests).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range requests {
+		if err := s.autoClose(&requests[i], cutoff); err != nil {
+			log.Printf("Warning: Failed to auto-close repair request %d: %v", requests[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// autoClose signs off one request as auto-closed. The update only matches while the
+// request is still completed before cutoff and unsigned, so a reopen, accept or
+// dispute since the request was read wins and the request is left alone.
+func (s *RepairRequestService) autoClose(request *models.RepairRequest, cutoff time.Time) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.RepairRequest{}).
+			Where("id = ? AND status = ? AND (sign_off = '' OR sign_off IS NULL) AND completed_at <= ?", request.ID, models.StatusCompleted, cutoff).
+			Updates(map[string]interface{}{
+				"sign_off":      models.SignOffAutoClosed,
+				"signed_off_at": time.Now(),
+				"version":       gorm.Expr("version + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := s.historyService.RecordChange(tx, request.ID, nil, HistoryFieldSignOff, "", string(models.SignOffAutoClosed)); err != nil {
+			return err
+		}
+		return s.outboxService.Enqueue(tx, models.EventRequestSignedOff, models.NotificationPayload{RepairRequestID: request.ID})
+	})
+}
+
+// Update validates and persists an edited request, records its history and queues its
+// notifications. before is the request as loaded; a concurrent change since then
+// returns ErrVersionConflict and a forbidden status change returns a *TransitionError.
+func (s *RepairRequestService) Update(request, before *models.RepairRequest, actor models.User) error {
+	costChanged := request.LaborCost != before.LaborCost || request.OtherCost != before.OtherCost
+	if costChanged && before.Status == models.StatusCompleted && actor.Role != models.RoleAdmin {
+		return ErrCompletedCostLocked
+	}
+
+	// Validate the status change against the workflow
+	if err := s.workflowService.ValidateTransition(before.Status, request, actor.Role); err != nil {
+		return err
+	}
+	// A request keeps what its status required, even when only other fields change
+	if request.Status == before.Status {
+		if err := s.workflowService.ValidateStatusFields(request); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	if request.Status == models.StatusCompleted && request.CompletedAt == nil {
+		request.CompletedAt = &now
+	}
+	// Every completion waits for a fresh sign-off
+	if request.Status == models.StatusCompleted && before.Status != models.StatusCompleted {
+		request.SignOff = ""
+		request.SignedOffAt = nil
+		request.Rating = nil
+		request.RatingComment = ""
+		request.DisputeReason = ""
+	}
+	if request.Status == models.StatusRejected && before.Status != models.StatusRejected {
+		request.RejectedByID = &actor.ID
+		request.RejectedAt = &now
+	}
+	return s.save(request, before, actor)
+}
+
+// save persists a validated change with its cost, history and notifications
+func (s *RepairRequestService) save(request, before *models.RepairRequest, actor models.User) error {
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		return s.persist(tx, request, before, actor)
+	})
+}
+
+// persist does the work of save inside the caller's transaction
+func (s *RepairRequestService) persist(tx *gorm.DB, request, before *models.RepairRequest, actor models.User) error {
+	if err := SaveRepairRequest(tx, request, before.Version); err != nil {
+		return err
+	}
+	if err := s.costService.Recalculate(tx, request); err != nil {
+		return err
+	}
+	if err := s.historyService.RecordChanges(tx, before, request, &actor.ID); err != nil {
+		return err
+	}
+	return s.enqueueNotifications(tx, before, request, actor.ID)
+}
+
+// enqueueNotifications queues a notification for every event the update caused
+func (s *RepairRequestService) enqueueNotifications(tx *gorm.DB, before, request *models.RepairRequest, actorID uint) error {
+	payload := models.NotificationPayload{RepairRequestID: request.ID, ActorID: &actorID, OldStatus: string(before.Status)}
+	events := []string{}
+
+	if request.Status != before.Status {
+		events = append(events, models.EventRequestStatusChanged)
+		switch request.Status {
+		case models.StatusCompleted:
+			events = append(events, models.EventRequestCompleted)
+		case models.StatusRejected:
+			events = append(events, models.EventRequestRejected)
+		}
+		if before.Status == models.StatusCompleted {
+			events = append(events, models.EventRequestReopened)
+		}
+	}
+	if request.TechnicianID != nil && (before.TechnicianID == nil || *before.TechnicianID != *request.TechnicianID) {
+		events = append(events, models.EventRequestAssigned)
+	}
+	if request.SignOff != "" && request.SignOff != before.SignOff {
+		events = append(events, models.EventRequestSignedOff)
+	}
+
+	for _, event := range events {
+		if err := s.outboxService.Enqueue(tx, event, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"time"
+
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// RepairRequestFilter holds the filters, sort order and page of a repair request listing
+type RepairRequestFilter struct {
+	Statuses      []models.RepairStatus
+	Priorities    []models.RepairPriority
+	CategoryID    *uint
+	TechnicianID  *uint
+	Unassigned    bool
+	RequesterID   *uint
+	Location      string
+	CreatedFrom   *time.Time
+	CreatedTo     *time.Time
+	CompletedFrom *time.Time
+	CompletedTo   *time.Time
+	Sort          string // created, updated, priority or age
+	Descending    bool
+	Page          int
+	Limit         int
+}
+
+// Pagination describes the page returned by a listing
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+}
+
+// RepairRequestPage is a page of repair requests with its metadata
+type RepairRequestPage struct {
+	Data       []models.RepairRequest `json:"data"`
+	Pagination Pagination             `json:"pagination"`
+}
+
+// VisibleRepairRequests scopes a repair request query to the rows the user may see:
+// admins see everything, technicians see their own and unassigned requests once approved,
+// and requesters see only the requests they raised. Designated category approvers also
+// see the requests in the categories they approve.
+func VisibleRepairRequests(user models.User) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		approverClause := "repair_requests.category_id IN (SELECT id FROM categories WHERE approver_id = ? AND deleted_at IS NULL)"
+		switch user.Role {
+		case models.RoleAdmin:
+			return db
+		case models.RoleTechnician:
+			return db.Where("((repair_requests.technician_id = ? OR repair_requests.technician_id IS NULL) AND repair_requests.status <> ?) OR "+approverClause,
+				user.ID, models.StatusAwaitingApproval, user.ID)
+		case models.RoleRequester:
+			return db.Where("repair_requests.requester_id = ? OR "+approverClause, user.ID, user.ID)
+		default:
+			return db.Where("1 = 0")
+		}
+	}
+}
+
+type RepairRequestQueryService struct{}
+
+func NewRepairRequestQueryService() *RepairRequestQueryService {
+	return &RepairRequestQueryService{}
+}
+
+// List runs the filter against the requests visible to user and returns the requested page
+func (s *RepairRequestQueryService) List(db *gorm.DB, user models.User, filter RepairRequestFilter) (*RepairRequestPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = DefaultPageLimit
+	}
+	if filter.Limit > MaxPageLimit {
+		filter.Limit = MaxPageLimit
+	}
+
+	query := s.applyFilter(db.Model(&models.RepairRequest{}).Scopes(VisibleRepairRequests(user)), filter)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	requests := []models.RepairRequest{}
+	err := s.applySort(query, filter).
+		Preload("Category").Preload("Requester").Preload("Technician").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((total + int64(filter.Limit) - 1) / int64(filter.Limit))
+	return &RepairRequestPage{
+		Data: requests,
+		Pagination: Pagination{
+			Page:       filter.Page,
+			Limit:      filter.Limit,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+func (s *RepairRequestQueryService) applyFilter(query *gorm.DB, filter RepairRequestFilter) *gorm.DB {
+	if len(filter.Statuses) > 0 {
+		query = query.Where("repair_requests.status IN ?", filter.Statuses)
+	}
+	if len(filter.Priorities) > 0 {
+		query = query.Where("repair_requests.priority IN ?", filter.Priorities)
+	}
+	if filter.CategoryID != nil {
+		query = query.Where("repair_requests.category_id = ?", *filter.CategoryID)
+	}
+	if filter.Unassigned {
+		query = query.Where("repair_requests.technician_id IS NULL")
+	} else if filter.TechnicianID != nil {
+		query = query.Where("repair_requests.technician_id = ?", *filter.TechnicianID)
+	}
+	if filter.RequesterID != nil {
+		query = query.Where("repair_requests.requester_id = ?", *filter.RequesterID)
+	}
+	if filter.Location != "" {
+		query = query.Where("LOWER(repair_requests.location) LIKE LOWER(?)", "%"+filter.Location+"%")
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("repair_requests.created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("repair_requests.created_at < ?", *filter.CreatedTo)
+	}
+	if filter.CompletedFrom != nil {
+		query = query.Where("repair_requests.completed_at >= ?", *filter.CompletedFrom)
+	}
+	if filter.CompletedTo != nil {
+		query = query.Where("repair_requests.completed_at < ?", *filter.CompletedTo)
+	}
+	return query
+}
+
+// applySort orders the query; the primary key is always the final tie-breaker so pages are stable
+func (s *RepairRequestQueryService) applySort(query *gorm.DB, filter RepairRequestFilter) *gorm.DB {
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+
+	switch filter.Sort {
+	case "updated":
+		query = query.Order("repair_requests.updated_at " + direction)
+	case "priority":
+		query = query.Order("CASE repair_requests.priority " +
+			"WHEN 'urgent' THEN 4 WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END " + direction)
+	case "age":
+		// The oldest request has the greatest age
+		ageDirection := "DESC"
+		if filter.Descending {
+			ageDirection = "ASC"
+		}
+		query = query.Order("repair_requests.created_at " + ageDirection)
+		direction = ageDirection
+	default:
+		query = query.Order("repair_requests.created_at " + direction)
+	}
+
+	return query.Order("repair_requests.id " + direction)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// RatingSummary aggregates requester sign-offs over a set of completed jobs
+type RatingSummary struct {
+	Completed     int64    `json:"completed"`
+	Rated         int64    `json:"rated"`
+	AverageRating *float64 `json:"averageRating"` // Nil when nothing was rated
+	Distribution  [5]int64 `json:"distribution"`  // Number of 1 to 5 star ratings
+	Disputed      int64    `json:"disputed"`
+	AutoClosed    int64    `json:"autoClosed"`
+	Awaiting      int64    `json:"awaiting"` // Completed jobs the requester has not signed off yet
+}
+
+type TechnicianRating struct {
+	TechnicianID uint   `json:"technicianId"`
+	FullName     string `json:"fullName"`
+	RatingSummary
+}
+
+type CategoryRating struct {
+	CategoryID uint   `json:"categoryId"`
+	Name       string `json:"name"`
+	RatingSummary
+}
+
+// ReportPeriod limits a report to jobs completed in [From, To). Either end may be nil.
+type ReportPeriod struct {
+	From *time.Time
+	To   *time.Time
+}
+
+// ratingRow is one aggregated group as scanned from the database
+type ratingRow struct {
+	GroupID       uint
+	Completed     int64
+	Rated         int64
+	AverageRating *float64
+	Stars1        int64
+	Stars2        int64
+	Stars3        int64
+	Stars4        int64
+	Stars5        int64
+	Disputed      int64
+	AutoClosed    int64
+	Awaiting      int64
+}
+
+func (r ratingRow) summary() RatingSummary {
+	return RatingSummary{
+		Completed:     r.Completed,
+		Rated:         r.Rated,
+		AverageRating: r.AverageRating,
+		Distribution:  [5]int64{r.Stars1, r.Stars2, r.Stars3, r.Stars4, r.Stars5},
+		Disputed:      r.Disputed,
+		AutoClosed:    r.AutoClosed,
+		Awaiting:      r.Awaiting,
+	}
+}
+
+// ratingColumns aggregates sign-offs; a plain AVG ignores unrated jobs
+const ratingColumns = `COUNT(*) AS completed,
+	COUNT(rating) AS rated,
+	AVG(rating) AS average_rating,
+	SUM(CASE WHEN rating = 1 THEN 1 ELSE 0 END) AS stars1,
+	SUM(CASE WHEN rating = 2 THEN 1 ELSE 0 END) AS stars2,
+	SUM(CASE WHEN rating = 3 THEN 1 ELSE 0 END) AS stars3,
+	SUM(CASE WHEN rating = 4 THEN 1 ELSE 0 END) AS stars4,
+	SUM(CASE WHEN rating = 5 THEN 1 ELSE 0 END) AS stars5,
+	SUM(CASE WHEN sign_off = 'disputed' THEN 1 ELSE 0 END) AS disputed,
+	SUM(CASE WHEN sign_off = 'auto_closed' THEN 1 ELSE 0 END) AS auto_closed,
+	SUM(CASE WHEN sign_off = '' OR sign_off IS NULL THEN 1 ELSE 0 END) AS awaiting`
+
+// ReportService builds the reporting endpoints' aggregates
+type ReportService struct{}
+
+func NewReportService() *ReportService {
+	return &ReportService{}
+}
+
+// TechnicianRatings aggregates the sign-offs of each technician's completed jobs
+func (s *ReportService) TechnicianRatings(period ReportPeriod) ([]TechnicianRating, RatingSummary, error) {
+	rows, overall, err := s.ratings("technician_id", period)
+	if err != nil {
+		return nil, overall, err
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.GroupID)
+	}
+	var users []models.User
+	if err := config.DB.Unscoped().Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, overall, err
+	}
+	names := map[uint]string{}
+	for _, user := range users {
+		names[user.ID] = user.FullName
+	}
+
+	ratings := make([]TechnicianRating, 0, len(rows))
+	for _, row := range rows {
+		ratings = append(ratings, TechnicianRating{TechnicianID: row.GroupID, FullName: names[row.GroupID], RatingSummary: row.summary()})
+	}
+	return ratings, overall, nil
+}
+
+// CategoryRatings aggregates the sign-offs of each category's completed jobs
+func (s *ReportService) CategoryRatings(period ReportPeriod) ([]CategoryRating, RatingSummary, error) {
+	rows, overall, err := s.ratings("category_id", period)
+	if err != nil {
+		return nil, overall, err
+	}
+
+	ids := make([]uint, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.GroupID)
+	}
+	var categories []models.Category
+	if err := config.DB.Unscoped().Where("id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, overall, err
+	}
+	names := map[uint]string{}
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	ratings := make([]CategoryRating, 0, len(rows))
+	for _, row := range rows {
+		ratings = append(ratings, CategoryRating{CategoryID: row.GroupID, Name: names[row.GroupID], RatingSummary: row.summary()})
+	}
+	return ratings, overall, nil
+}
+
+// ratings groups completed jobs by column and also returns the totals over every group
+func (s *ReportService) ratings(column string, period ReportPeriod) ([]ratingRow, RatingSummary, error) {
+	var rows []ratingRow
+	err := s.completedJobs(period).
+		Select(column + " AS group_id, " + ratingColumns).
+		Where(column + " IS NOT NULL").
+		Group(column).
+		Order(column).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, RatingSummary{}, err
+	}
+
+	var overall ratingRow
+	if err := s.completedJobs(period).Select(ratingColumns).Scan(&overall).Error; err != nil {
+		return nil, RatingSummary{}, err
+	}
+	return rows, overall.summary(), nil
+}
+
+func (s *ReportService) completedJobs(period ReportPeriod) *gorm.DB {
+	query := config.DB.Model(&models.RepairRequest{}).Where("status = ?", models.StatusCompleted)
+	if period.From != nil {
+		query = query.Where("completed_at >= ?", *period.From)
+	}
+	if period.To != nil {
+		query = query.Where("completed_at < ?", *period.To)
+	}
+	return query
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"repair-system/models"
+
+	"gorm.io/gorm/clause"
+)
+
+func TestRatingReports(t *testing.T) {
+	db := setupTestDB(t)
+	inMay := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	inApril := time.Date(2024, 4, 10, 12, 0, 0, 0, time.UTC)
+
+	categories := []models.Category{{Name: "Air conditioning"}, {Name: "Plumbing"}}
+	technicians := []models.User{
+		{Username: "somchai", Email: "somchai@example.com", Password: "x", FullName: "Somchai", Role: models.RoleTechnician},
+		{Username: "malee", Email: "malee@example.com", Password: "x", FullName: "Malee", Role: models.RoleTechnician},
+	}
+	if err := db.Create(&categories).Error; err != nil {
+		t.Fatalf("create categories: %v", err)
+	}
+	if err := db.Create(&technicians).Error; err != nil {
+		t.Fatalf("create technicians: %v", err)
+	}
+
+	job := func(technician *models.User, category models.Category, status models.RepairStatus, completedAt time.Time, signOff models.SignOffStatus, rating int) models.RepairRequest {
+		request := models.RepairRequest{
+			Title:       "Job",
+			Description: "Job",
+			CategoryID:  category.ID,
+			Status:      status,
+			CompletedAt: &completedAt,
+			SignOff:     signOff,
+		}
+		if technician != nil {
+			request.TechnicianID = &technician.ID
+		}
+		if rating > 0 {
+			request.Rating = &rating
+		}
+		return request
+	}
+	somchai, malee := &technicians[0], &technicians[1]
+	aircon, plumbing := categories[0], categories[1]
+	requests := []models.RepairRequest{
+		job(somchai, aircon, models.StatusCompleted, inMay, models.SignOffAccepted, 5),
+		job(somchai, aircon, models.StatusCompleted, inMay, models.SignOffAccepted, 3),
+		job(somchai, plumbing, models.StatusCompleted, inMay, models.SignOffDisputed, 0),
+		job(malee, plumbing, models.StatusCompleted, inMay, models.SignOffAutoClosed, 0),
+		job(malee, plumbing, models.StatusCompleted, inMay, "", 0),
+		job(nil, aircon, models.StatusCompleted, inMay, models.SignOffAccepted, 4),
+		// Not completed, or completed outside the period
+		job(malee, aircon, models.StatusInProgress, inMay, "", 0),
+		job(somchai, aircon, models.StatusCompleted, inApril, models.SignOffAccepted, 1),
+	}
+	if err := db.Omit(clause.Associations).Create(&requests).Error; err != nil {
+		t.Fatalf("create requests: %v", err)
+	}
+
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	may := ReportPeriod{From: &from, To: &to}
+	average := func(value float64) *float64 { return &value }
+	overall := RatingSummary{Completed: 6, Rated: 3, AverageRating: average(4), Distribution: [5]int64{0, 0, 1, 1, 1}, Disputed: 1, AutoClosed: 1, Awaiting: 1}
+
+	service := NewReportService()
+	t.Run("technicians", func(t *testing.T) {
+		ratings, gotOverall, err := service.TechnicianRatings(may)
+		if err != nil {
+			t.Fatalf("TechnicianRatings: %v", err)
+		}
+		// Jobs without a technician count towards the totals only
+		assertRatingSummary(t, "overall", gotOverall, overall)
+		if len(ratings) != 2 {
+			t.Fatalf("got %d technicians, want 2: %+v", len(ratings), ratings)
+		}
+		if ratings[0].TechnicianID != somchai.ID || ratings[0].FullName != "Somchai" || ratings[1].FullName != "Malee" {
+			t.Errorf("technicians = %+v", ratings)
+		}
+		assertRatingSummary(t, "Somchai", ratings[0].RatingSummary, RatingSummary{Completed: 3, Rated: 2, AverageRating: average(4), Distribution: [5]int64{0, 0, 1, 0, 1}, Disputed: 1})
+		assertRatingSummary(t, "Malee", ratings[1].RatingSummary, RatingSummary{Completed: 2, AutoClosed: 1, Awaiting: 1})
+	})
+
+	t.Run("categories", func(t *testing.T) {
+		ratings, gotOverall, err := service.CategoryRatings(may)
+		if err != nil {
+			t.Fatalf("CategoryRatings: %v", err)
+		}
+		assertRatingSummary(t, "overall", gotOverall, overall)
+		if len(ratings) != 2 || ratings[0].Name != "Air conditioning" || ratings[1].Name != "Plumbing" {
+			t.Fatalf("categories = %+v", ratings)
+		}
+		assertRatingSummary(t, "Air conditioning", ratings[0].RatingSummary, RatingSummary{Completed: 3, Rated: 3, AverageRating: average(4), Distribution: [5]int64{0, 0, 1, 1, 1}})
+		assertRatingSummary(t, "Plumbing", ratings[1].RatingSummary, RatingSummary{Completed: 3, Disputed: 1, AutoClosed: 1, Awaiting: 1})
+	})
+
+	t.Run("without period", func(t *testing.T) {
+		ratings, _, err := service.TechnicianRatings(ReportPeriod{})
+		if err != nil {
+			t.Fatalf("TechnicianRatings: %v", err)
+		}
+		assertRatingSummary(t, "Somchai", ratings[0].RatingSummary, RatingSummary{Completed: 4, Rated: 3, AverageRating: average(3), Distribution: [5]int64{1, 0, 1, 0, 1}, Disputed: 1})
+	})
+}
+
+func assertRatingSummary(t *testing.T, name string, got, want RatingSummary) {
+	t.Helper()
+	if (got.AverageRating == nil) != (want.AverageRating == nil) ||
+		(got.AverageRating != nil && *got.AverageRating != *want.AverageRating) {
+		t.Errorf("%s average = %v, want %v", name, formatAverage(got.AverageRating), formatAverage(want.AverageRating))
+	}
+	got.AverageRating, want.AverageRating = nil, nil
+	if got != want {
+		t.Errorf("%s = %+v, want %+v", name, got, want)
+	}
+}
+
+func formatAverage(average *float64) interface{} {
+	if average == nil {
+		return "nil"
+	}
+	return *average
+}
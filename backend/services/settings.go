@@ -117,6 +117,8 @@ func (s *SettingsService) GetAllSettings() (map[string]string, error) {
 func (s *SettingsService) isSensitiveKey(key string) bool {
 	sensitiveKeys := []string{
 		models.SettingTelegramBotToken,
+		models.SettingSMTPPassword,
+		models.SettingTelegramWebhookSecret,
 		// Add more sensitive keys here as needed
 	}
 
@@ -136,13 +138,24 @@ func (s *SettingsService) InitializeDefaultSettings() error {
 		models.SettingTelegramNotifyStatusChange: "true",
 		models.SettingTelegramNotifyAssignment:   "true",
 		models.SettingTelegramNotifyCompletion:   "true",
+		models.SettingTelegramNotifyRejection:    "true",
+		models.SettingTelegramUpdateMode:         "off",
+		models.SettingEmailEnabled:               "false",
+		models.SettingSMTPPort:                   "587",
+		models.SettingSMTPTLSMode:                "starttls",
 		models.SettingSiteName:                   "Repair System",
 		models.SettingSiteDescription:            "ระบบแจ้งซ่อมออนไลน์",
 		models.SettingAdminEmail:                 "admin@example.com",
 		models.SettingAutoAssignTechnicians:      "false",
+		models.SettingAutoAssignStrategy:         "least_workload",
 		models.SettingRequireApproval:            "true",
 		models.SettingDefaultPriority:            "medium",
 		models.SettingMaintenanceMode:            "false",
+		models.SettingMaintenanceMessage:         "ระบบอยู่ระหว่างปิดปรับปรุง กรุณาลองใหม่ภายหลัง",
+		models.SettingMaintenanceRetryAfter:      "300",
+		models.SettingNotificationLocale:         "th",
+		models.SettingReopenWindowDays:           "7",
+		models.SettingSignOffDays:                "7",
 	}
 
 	for key, defaultValue := range defaults {
@@ -2,27 +2,90 @@ package services
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"repair-system/models"
 )
 
+const (
+	DefaultTelegramAPIBaseURL = "https://api.telegram.org"
+	telegramRequestTimeout    = 15 * time.Second
+)
+
 type TelegramService struct {
-	BotToken        string
-	ChatID          string
-	Enabled         bool
+	BotToken   string
+	ChatID     string
+	Enabled    bool
+	APIBaseURL string // Defaults to DefaultTelegramAPIBaseURL
+	ProxyURL   string // Defaults to the proxy from the environment
+	// HTTPClient, when set, is used for every Bot API call instead of the shared
+	// client built from ProxyURL. Calls still carry their own timeout.
+	HTTPClient      *http.Client
 	settingsService *SettingsService
+	templates       *NotificationTemplateService
 }
 
 type TelegramMessage struct {
-	ChatID    string `json:"chat_id"`
-	Text      string `json:"text"`
-	ParseMode string `json:"parse_mode,omitempty"`
+	ChatID      string                  `json:"chat_id"`
+	Text        string                  `json:"text"`
+	ParseMode   string                  `json:"parse_mode,omitempty"`
+	ReplyMarkup *TelegramInlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+// TelegramInlineKeyboard is a grid of buttons shown under a message
+type TelegramInlineKeyboard struct {
+	InlineKeyboard [][]TelegramInlineButton `json:"inline_keyboard"`
+}
+
+type TelegramInlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+// TelegramAPIError is returned when the Bot API answers {"ok": false}
+type TelegramAPIError struct {
+	Method          string
+	StatusCode      int
+	ErrorCode       int
+	Description     string
+	MigrateToChatID int64 // Set when a group was upgraded to a supergroup
+}
+
+func (e *TelegramAPIError) Error() string {
+	msg := fmt.Sprintf("telegram %s failed with status %d", e.Method, e.StatusCode)
+	if e.Description != "" {
+		msg += ": " + e.Description
+	}
+	if e.MigrateToChatID != 0 {
+		msg += fmt.Sprintf(" (chat moved to %d)", e.MigrateToChatID)
+	}
+	return msg
+}
+
+// TelegramRateLimitError is returned when Telegram answers 429 Too Many Requests
+type TelegramRateLimitError struct {
+	Seconds     int
+	Description string
+}
+
+func (e *TelegramRateLimitError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("telegram rate limit exceeded, retry after %d seconds: %s", e.Seconds, e.Description)
+	}
+	return fmt.Sprintf("telegram rate limit exceeded, retry after %d seconds", e.Seconds)
+}
+
+// RetryAfter implements RetryAfterError
+func (e *TelegramRateLimitError) RetryAfter() time.Duration {
+	return time.Duration(e.Seconds) * time.Second
 }
 
 func NewTelegramService() *TelegramService {
@@ -42,7 +105,10 @@ func NewTelegramService() *TelegramService {
 		BotToken:        botToken,
 		ChatID:          chatID,
 		Enabled:         enabled,
+		APIBaseURL:      settingsService.GetSettingWithDefault(models.SettingTelegramAPIBaseURL, os.Getenv("TELEGRAM_API_BASE_URL")),
+		ProxyURL:        settingsService.GetSettingWithDefault(models.SettingTelegramProxyURL, os.Getenv("TELEGRAM_PROXY_URL")),
 		settingsService: settingsService,
+		templates:       NewNotificationTemplateService(settingsService),
 	}
 }
 
@@ -61,21 +127,56 @@ func NewTelegramServiceWithSettings(settingsService *SettingsService) *TelegramS
 		BotToken:        botToken,
 		ChatID:          chatID,
 		Enabled:         enabled,
+		APIBaseURL:      settingsService.GetSettingWithDefault(models.SettingTelegramAPIBaseURL, os.Getenv("TELEGRAM_API_BASE_URL")),
+		ProxyURL:        settingsService.GetSettingWithDefault(models.SettingTelegramProxyURL, os.Getenv("TELEGRAM_PROXY_URL")),
 		settingsService: settingsService,
+		templates:       NewNotificationTemplateService(settingsService),
 	}
 }
 
+// Channel implements Notifier
+func (s *TelegramService) Channel() string {
+	return "telegram"
+}
+
+// Audiences implements Notifier; every event goes to the group chat
+func (s *TelegramService) Audiences(event string) []string {
+	return []string{NotificationAudienceGroup}
+}
+
 func (s *TelegramService) IsEnabled() bool {
 	// Refresh settings from database for the latest values
 	s.refreshSettings()
 	return s.Enabled && s.BotToken != "" && s.ChatID != ""
 }
 
+// CanSendDirect reports whether the bot is configured; private chats don't need a group chat ID
+func (s *TelegramService) CanSendDirect() bool {
+	s.refreshSettings()
+	return s.Enabled && s.BotToken != ""
+}
+
+// ForChat returns a copy of the service that sends to the given chat instead of the group
+func (s *TelegramService) ForChat(chatID string) *TelegramService {
+	s.refreshSettings()
+	return &TelegramService{
+		BotToken:   s.BotToken,
+		ChatID:     chatID,
+		Enabled:    s.Enabled,
+		APIBaseURL: s.APIBaseURL,
+		ProxyURL:   s.ProxyURL,
+		HTTPClient: s.HTTPClient,
+		templates:  s.templates,
+	}
+}
+
 func (s *TelegramService) refreshSettings() {
 	if s.settingsService != nil {
 		s.BotToken = s.settingsService.GetSettingWithDefault(models.SettingTelegramBotToken, os.Getenv("TELEGRAM_BOT_TOKEN"))
 		s.ChatID = s.settingsService.GetSettingWithDefault(models.SettingTelegramChatID, os.Getenv("TELEGRAM_CHAT_ID"))
 		s.Enabled = s.settingsService.GetBoolSetting(models.SettingTelegramEnabled)
+		s.APIBaseURL = s.settingsService.GetSettingWithDefault(models.SettingTelegramAPIBaseURL, os.Getenv("TELEGRAM_API_BASE_URL"))
+		s.ProxyURL = s.settingsService.GetSettingWithDefault(models.SettingTelegramProxyURL, os.Getenv("TELEGRAM_PROXY_URL"))
 
 		// Fallback to environment variable if database setting is not set
 		if !s.Enabled {
@@ -85,291 +186,238 @@ func (s *TelegramService) refreshSettings() {
 }
 
 func (s *TelegramService) SendMessage(message string) error {
+	return s.SendMessageWithKeyboard(message, nil)
+}
+
+// SendMessageWithKeyboard sends a message with inline buttons underneath
+func (s *TelegramService) SendMessageWithKeyboard(message string, keyboard *TelegramInlineKeyboard) error {
 	if !s.IsEnabled() {
 		return nil // Silently skip if not enabled
 	}
 
 	telegramMsg := TelegramMessage{
-		ChatID:    s.ChatID,
-		Text:      message,
-		ParseMode: "HTML",
+		ChatID:      s.ChatID,
+		Text:        message,
+		ParseMode:   "HTML",
+		ReplyMarkup: keyboard,
 	}
 
-	jsonData, err := json.Marshal(telegramMsg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal telegram message: %v", err)
+	if err := s.call("sendMessage", telegramMsg, nil); err != nil {
+		return fmt.Errorf("failed to send telegram message: %w", err)
 	}
+	return nil
+}
 
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.BotToken)
+// call invokes a Bot API method and decodes its result into result, if given
+func (s *TelegramService) call(method string, payload interface{}, result interface{}) error {
+	return s.callWithTimeout(method, payload, result, telegramRequestTimeout)
+}
 
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(jsonData))
+// callWithTimeout is call for methods that hold the request open, such as getUpdates
+func (s *TelegramService) callWithTimeout(method string, payload interface{}, result interface{}, timeout time.Duration) error {
+	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to send telegram message: %v", err)
+		return fmt.Errorf("failed to marshal telegram request: %v", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("telegram API returned status: %d", resp.StatusCode)
+	client, err := s.client()
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-func (s *TelegramService) NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error {
-	if !s.IsEnabled() {
-		return nil
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.methodURL(method), bytes.NewReader(jsonData))
+	if err != nil {
+		return err
 	}
+	req.Header.Set("Content-Type", "application/json")
 
-	priorityEmoji := s.getPriorityEmoji(string(request.Priority))
-	statusEmoji := s.getStatusEmoji(string(request.Status))
-
-	message := fmt.Sprintf(`🔧 <b>แจ้งซ่อมใหม่</b>
-
-%s <b>%s</b> %s
-
-📋 <b>รายละเอียด:</b>
-• หัวข้อ: %s
-• รายละเอียด: %s
-• สถานที่: %s
-• ระดับความสำคัญ: %s %s
-• สถานะ: %s %s
+	resp, err := client.Do(req)
+	if err != nil {
+		// The URL holds the bot token, so keep it out of logs and outbox errors
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s request failed: %w", method, err)
+	}
+	defer resp.Body.Close()
 
-👤 <b>ผู้แจ้ง:</b> %s (%s)
-🕐 <b>เวลา:</b> %s
+	// Every Bot API answer, success or not, is {ok, result} or {ok, error_code, description, parameters}
+	var body struct {
+		OK          bool            `json:"ok"`
+		Result      json.RawMessage `json:"result"`
+		ErrorCode   int             `json:"error_code"`
+		Description string          `json:"description"`
+		Parameters  struct {
+			RetryAfter      int   `json:"retry_after"`
+			MigrateToChatID int64 `json:"migrate_to_chat_id"`
+		} `json:"parameters"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&body)
 
-#แจ้งซ่อม #ใหม่ #%s`,
-		priorityEmoji,
-		request.Title,
-		statusEmoji,
-		request.Title,
-		s.truncateText(request.Description, 100),
-		s.getLocationText(&request.Location),
-		s.getPriorityText(string(request.Priority)),
-		priorityEmoji,
-		s.getStatusText(string(request.Status)),
-		statusEmoji,
-		requester.FullName,
-		requester.Username,
-		request.CreatedAt.Format("02/01/2006 15:04"),
-		strings.ToLower(string(request.Priority)))
+	if resp.StatusCode == http.StatusTooManyRequests || body.Parameters.RetryAfter > 0 {
+		return &TelegramRateLimitError{Seconds: body.Parameters.RetryAfter, Description: body.Description}
+	}
+	if resp.StatusCode != http.StatusOK || !body.OK {
+		return &TelegramAPIError{
+			Method:          method,
+			StatusCode:      resp.StatusCode,
+			ErrorCode:       body.ErrorCode,
+			Description:     body.Description,
+			MigrateToChatID: body.Parameters.MigrateToChatID,
+		}
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode telegram response: %v", decodeErr)
+	}
 
-	return s.SendMessage(message)
+	if result != nil {
+		return json.Unmarshal(body.Result, result)
+	}
+	return nil
 }
 
-func (s *TelegramService) NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error {
-	if !s.IsEnabled() {
-		return nil
+func (s *TelegramService) methodURL(method string) string {
+	baseURL := strings.TrimRight(s.APIBaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultTelegramAPIBaseURL
 	}
+	return fmt.Sprintf("%s/bot%s/%s", baseURL, s.BotToken, method)
+}
 
-	statusEmoji := s.getStatusEmoji(string(request.Status))
-	oldStatusEmoji := s.getStatusEmoji(oldStatus)
-
-	message := fmt.Sprintf(`🔄 <b>เปลี่ยนสถานะงานซ่อม</b>
+func (s *TelegramService) client() (*http.Client, error) {
+	if s.HTTPClient != nil {
+		return s.HTTPClient, nil
+	}
+	return telegramHTTPClient(s.ProxyURL)
+}
 
-📋 <b>งาน:</b> %s
+// Clients are shared per proxy so connections to Telegram are reused between calls
+var (
+	telegramClientsMu sync.Mutex
+	telegramClients   = map[string]*http.Client{}
+)
 
-🔄 <b>สถานะ:</b>
-%s %s ➡️ %s %s
+// telegramHTTPClient returns the shared client for the proxy, or for the environment's
+// proxy settings when proxyURL is empty
+func telegramHTTPClient(proxyURL string) (*http.Client, error) {
+	telegramClientsMu.Lock()
+	defer telegramClientsMu.Unlock()
 
-👤 <b>ช่าง:</b> %s
-🕐 <b>เวลา:</b> %s
+	if client, ok := telegramClients[proxyURL]; ok {
+		return client, nil
+	}
 
-#เปลี่ยนสถานะ #%s`,
-		request.Title,
-		oldStatusEmoji,
-		s.getStatusText(oldStatus),
-		statusEmoji,
-		s.getStatusText(string(request.Status)),
-		s.getTechnicianName(technician),
-		time.Now().Format("02/01/2006 15:04"),
-		strings.ToLower(string(request.Status)))
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL != "" {
+		proxy, err := ParseTelegramProxyURL(proxyURL)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
 
-	return s.SendMessage(message)
+	client := &http.Client{Transport: transport}
+	telegramClients[proxyURL] = client
+	return client, nil
 }
 
-func (s *TelegramService) NotifyAssignment(request *models.RepairRequest, technician *models.User) error {
-	if !s.IsEnabled() {
-		return nil
+// ParseTelegramProxyURL checks that proxyURL is an http, https or socks5 proxy
+func ParseTelegramProxyURL(proxyURL string) (*url.URL, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid telegram proxy URL")
 	}
+	switch proxy.Scheme {
+	case "http", "https", "socks5":
+		return proxy, nil
+	default:
+		return nil, fmt.Errorf("telegram proxy URL must use http, https or socks5")
+	}
+}
 
-	priorityEmoji := s.getPriorityEmoji(string(request.Priority))
-
-	message := fmt.Sprintf(`👷‍♂️ <b>มอบหมายงานซ่อม</b>
-
-📋 <b>งาน:</b> %s
-🔧 <b>ช่างที่รับผิดชอบ:</b> %s
-⚡ <b>ระดับความสำคัญ:</b> %s %s
-
-📍 <b>สถานที่:</b> %s
-📅 <b>เวลาที่มอบหมาย:</b> %s
-
-#มอบหมายงาน #%s`,
-		request.Title,
-		technician.FullName,
-		s.getPriorityText(string(request.Priority)),
-		priorityEmoji,
-		s.getLocationText(&request.Location),
-		time.Now().Format("02/01/2006 15:04"),
-		technician.Username)
+func (s *TelegramService) NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error {
+	return s.notify(models.EventRequestCreated, NotificationData{Request: request, Actor: requester})
+}
 
-	return s.SendMessage(message)
+func (s *TelegramService) NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error {
+	return s.notify(models.EventRequestStatusChanged, NotificationData{Request: request, Technician: technician, OldStatus: oldStatus})
 }
 
-func (s *TelegramService) NotifyCompletion(request *models.RepairRequest, technician *models.User) error {
+func (s *TelegramService) NotifyAssignment(request *models.RepairRequest, technician *models.User) error {
 	if !s.IsEnabled() {
 		return nil
 	}
 
-	duration := ""
-	if request.CompletedAt != nil && !request.CreatedAt.IsZero() {
-		d := request.CompletedAt.Sub(request.CreatedAt)
-		duration = s.formatDuration(d)
+	message, err := s.render(models.EventRequestAssigned, NotificationData{Request: request, Technician: technician, Recipient: technician})
+	if err != nil {
+		return err
 	}
+	return s.SendMessageWithKeyboard(message, JobKeyboard(request.ID))
+}
 
-	message := fmt.Sprintf(`✅ <b>งานซ่อมเสร็จสิ้น</b>
-
-📋 <b>งาน:</b> %s
-🔧 <b>ช่าง:</b> %s
-⏱️ <b>ระยะเวลา:</b> %s
-💰 <b>ค่าใช้จ่าย:</b> %s
-
-📅 <b>เสร็จสิ้นเมื่อ:</b> %s
-
-#เสร็จสิ้น #สำเร็จ`,
-		request.Title,
-		technician.FullName,
-		duration,
-		s.getCostText(&request.Cost),
-		request.CompletedAt.Format("02/01/2006 15:04"))
-
-	return s.SendMessage(message)
+func (s *TelegramService) NotifyCompletion(request *models.RepairRequest, technician *models.User) error {
+	return s.notify(models.EventRequestCompleted, NotificationData{Request: request, Technician: technician})
 }
 
-func (s *TelegramService) NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error {
+// NotifyReopen implements ReopenNotifier. The job buttons let the technician pick it up again.
+func (s *TelegramService) NotifyReopen(request *models.RepairRequest, technician *models.User, reason string) error {
 	if !s.IsEnabled() {
 		return nil
 	}
 
-	message := fmt.Sprintf(`❌ <b>ปฏิเสธงานซ่อม</b>
-
-📋 <b>งาน:</b> %s
-👤 <b>ผู้ปฏิเสธ:</b> %s
-
-📝 <b>เหตุผล:</b>
-%s
-
-📅 <b>เวลา:</b> %s
-
-#ปฏิเสธ #ยกเลิก`,
-		request.Title,
-		admin.FullName,
-		reason,
-		time.Now().Format("02/01/2006 15:04"))
-
-	return s.SendMessage(message)
+	message, err := s.render(models.EventRequestReopened, NotificationData{
+		Request: request, Technician: technician, Recipient: technician, Actor: &request.Requester,
+		Reason: reason, OldStatus: string(models.StatusCompleted),
+	})
+	if err != nil {
+		return err
+	}
+	return s.SendMessageWithKeyboard(message, JobKeyboard(request.ID))
 }
 
-// Helper functions
-func (s *TelegramService) getPriorityEmoji(priority string) string {
-	switch priority {
-	case "urgent":
-		return "🚨"
-	case "high":
-		return "🔴"
-	case "medium":
-		return "🟡"
-	case "low":
-		return "🟢"
-	default:
-		return "⚪"
-	}
-}
-
-func (s *TelegramService) getStatusEmoji(status string) string {
-	switch status {
-	case "pending":
-		return "⏳"
-	case "in_progress":
-		return "🔧"
-	case "waiting_part":
-		return "📦"
-	case "completed":
-		return "✅"
-	case "rejected":
-		return "❌"
-	default:
-		return "❓"
-	}
+// NotifySignOff implements SignOffNotifier
+func (s *TelegramService) NotifySignOff(request *models.RepairRequest, technician *models.User, requester *models.User) error {
+	return s.notify(models.EventRequestSignedOff, NotificationData{
+		Request: request, Technician: technician, Recipient: technician, Actor: requester, Reason: request.DisputeReason,
+	})
 }
 
-func (s *TelegramService) getPriorityText(priority string) string {
-	switch priority {
-	case "urgent":
-		return "เร่งด่วน"
-	case "high":
-		return "สูง"
-	case "medium":
-		return "ปานกลาง"
-	case "low":
-		return "ต่ำ"
-	default:
-		return priority
-	}
-}
-
-func (s *TelegramService) getStatusText(status string) string {
-	switch status {
-	case "pending":
-		return "รอดำเนินการ"
-	case "in_progress":
-		return "กำลังดำเนินการ"
-	case "waiting_part":
-		return "รออะไหล่"
-	case "completed":
-		return "เสร็จสิ้น"
-	case "rejected":
-		return "ปฏิเสธ"
-	default:
-		return status
-	}
+func (s *TelegramService) NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error {
+	return s.notify(models.EventRequestRejected, NotificationData{Request: request, Actor: admin, Reason: reason})
 }
 
-func (s *TelegramService) getTechnicianName(technician *models.User) string {
-	if technician != nil {
-		return technician.FullName
-	}
-	return "ยังไม่ได้มอบหมาย"
+func (s *TelegramService) NotifyNewComment(request *models.RepairRequest, comment *models.Comment, author *models.User, recipient *models.User) error {
+	return s.notify(models.EventCommentAdded, NotificationData{Request: request, Comment: comment, Actor: author, Recipient: recipient})
 }
 
-func (s *TelegramService) getLocationText(location *string) string {
-	if location != nil && *location != "" {
-		return *location
-	}
-	return "ไม่ระบุ"
+func (s *TelegramService) NotifyApproval(request *models.RepairRequest, approval *models.Approval, oldStatus string) error {
+	return s.notify(models.EventRequestApproval, NotificationData{Request: request, Approval: approval, Actor: &approval.Approver, OldStatus: oldStatus})
 }
 
-func (s *TelegramService) getCostText(cost *float64) string {
-	if cost != nil && *cost > 0 {
-		return fmt.Sprintf("%.2f บาท", *cost)
+// notify renders the event's template and sends it
+func (s *TelegramService) notify(event string, data NotificationData) error {
+	if !s.IsEnabled() {
+		return nil
 	}
-	return "ไม่มีค่าใช้จ่าย"
-}
 
-func (s *TelegramService) truncateText(text string, maxLength int) string {
-	if len(text) <= maxLength {
-		return text
+	message, err := s.render(event, data)
+	if err != nil {
+		return err
 	}
-	return text[:maxLength] + "..."
+	return s.SendMessage(message)
 }
 
-func (s *TelegramService) formatDuration(d time.Duration) string {
-	hours := int(d.Hours())
-	if hours < 1 {
-		return fmt.Sprintf("%d นาที", int(d.Minutes()))
-	} else if hours < 24 {
-		return fmt.Sprintf("%d ชั่วโมง", hours)
-	} else {
-		days := hours / 24
-		return fmt.Sprintf("%d วัน", days)
+func (s *TelegramService) render(event string, data NotificationData) (string, error) {
+	templates := s.templates
+	if templates == nil {
+		templates = NewNotificationTemplateService(NewSettingsService())
+	}
+	rendered, err := templates.RenderEvent(s.Channel(), event, data)
+	if err != nil {
+		return "", err
 	}
+	return rendered.Body, nil
 }
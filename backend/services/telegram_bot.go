@@ -0,0 +1,268 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// How the bot receives updates from Telegram
+const (
+	TelegramUpdateModeOff     = "off"
+	TelegramUpdateModePolling = "polling" // Long-poll getUpdates from this server
+	TelegramUpdateModeWebhook = "webhook" // Telegram posts to /api/telegram/webhook
+)
+
+const (
+	telegramLinkCodeTTL  = 15 * time.Minute
+	telegramPollTimeout  = 30 // Seconds Telegram holds a getUpdates request open
+	telegramPollInterval = 10 * time.Second
+)
+
+var ErrInvalidLinkCode = errors.New("link code is invalid or has expired")
+
+// TelegramUpdate is an incoming update from the Bot API
+type TelegramUpdate struct {
+	UpdateID      int64                   `json:"update_id"`
+	Message       *TelegramInboundMessage `json:"message"`
+	CallbackQuery *TelegramCallbackQuery  `json:"callback_query"`
+}
+
+// TelegramCallbackQuery is sent when someone presses an inline keyboard button
+type TelegramCallbackQuery struct {
+	ID      string                  `json:"id"`
+	From    TelegramUser            `json:"from"`
+	Message *TelegramInboundMessage `json:"message"`
+	Data    string                  `json:"data"`
+}
+
+type TelegramInboundMessage struct {
+	MessageID int64         `json:"message_id"`
+	From      *TelegramUser `json:"from"`
+	Chat      TelegramChat  `json:"chat"`
+	Text      string        `json:"text"`
+}
+
+type TelegramUser struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	FirstName string `json:"first_name"`
+}
+
+type TelegramChat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"` // private, group, supergroup or channel
+}
+
+// TelegramBotService links user accounts to Telegram chats and answers messages sent to the bot
+type TelegramBotService struct {
+	settingsService      *SettingsService
+	telegram             *TelegramService
+	repairRequestService *RepairRequestService
+	queryService         *RepairRequestQueryService
+}
+
+func NewTelegramBotService(settingsService *SettingsService) *TelegramBotService {
+	return &TelegramBotService{
+		settingsService:      settingsService,
+		telegram:             NewTelegramServiceWithSettings(settingsService),
+		repairRequestService: NewRepairRequestService(settingsService),
+		queryService:         NewRepairRequestQueryService(),
+	}
+}
+
+// BotUsername returns the bot's username, asking Telegram once and remembering it
+func (s *TelegramBotService) BotUsername() (string, error) {
+	if username := s.settingsService.GetSettingWithDefault(models.SettingTelegramBotUsername, ""); username != "" {
+		return username, nil
+	}
+	if !s.telegram.CanSendDirect() {
+		return "", errors.New("telegram bot is not configured")
+	}
+
+	var me TelegramUser
+	if err := s.telegram.call("getMe", struct{}{}, &me); err != nil {
+		return "", fmt.Errorf("failed to look up bot username: %w", err)
+	}
+	if err := s.settingsService.SetSetting(models.SettingTelegramBotUsername, me.Username); err != nil {
+		return "", err
+	}
+	return me.Username, nil
+}
+
+// CreateLinkCode issues a fresh one-time code for the user, replacing any unused one,
+// and returns it with the deep link that sends it to the bot
+func (s *TelegramBotService) CreateLinkCode(user *models.User) (*models.TelegramLinkCode, string, error) {
+	username, err := s.BotUsername()
+	if err != nil {
+		return nil, "", err
+	}
+
+	code, err := randomHex(16)
+	if err != nil {
+		return nil, "", err
+	}
+	linkCode := models.TelegramLinkCode{
+		UserID:    user.ID,
+		Code:      code,
+		ExpiresAt: time.Now().Add(telegramLinkCodeTTL),
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.TelegramLinkCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&linkCode).Error
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &linkCode, fmt.Sprintf("https://t.me/%s?start=%s", username, code), nil
+}
+
+// Link binds the chat to the owner of the code. A chat belongs to one user at a time.
+func (s *TelegramBotService) Link(code string, chatID int64) (*models.User, error) {
+	var user models.User
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		var linkCode models.TelegramLinkCode
+		err := tx.Where("code = ? AND used_at IS NULL AND expires_at > ?", code, time.Now()).First(&linkCode).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidLinkCode
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&linkCode).Update("used_at", &now).Error; err != nil {
+			return err
+		}
+
+		chat := strconv.FormatInt(chatID, 10)
+		if err := tx.Model(&models.User{}).Where("telegram_id = ? AND id <> ?", chat, linkCode.UserID).
+			Update("telegram_id", "").Error; err != nil {
+			return err
+		}
+		if err := tx.First(&user, linkCode.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("telegram_id", chat).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Unlink forgets the user's Telegram chat
+func (s *TelegramBotService) Unlink(user *models.User) error {
+	return config.DB.Model(user).Update("telegram_id", "").Error
+}
+
+// HandleUpdate processes one update, whether it came from the webhook or from polling
+func (s *TelegramBotService) HandleUpdate(update TelegramUpdate) error {
+	if update.CallbackQuery != nil {
+		return s.handleCallback(update.CallbackQuery)
+	}
+
+	msg := update.Message
+	if msg == nil || !strings.HasPrefix(msg.Text, "/") {
+		return nil
+	}
+
+	command, args := s.parseCommand(msg.Text)
+	switch command {
+	case "/start":
+		return s.handleStart(msg, args)
+	case "/help":
+		return s.reply(msg.Chat.ID, telegramHelpText)
+	case "/my", "/job", "/accept", "/status", "/note":
+		return s.handleJobCommand(msg, command, args)
+	default:
+		return nil
+	}
+}
+
+// parseCommand splits "/cmd@bot arg1 arg2" into "/cmd" and its arguments
+func (s *TelegramBotService) parseCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	command := strings.ToLower(fields[0])
+	if i := strings.Index(command, "@"); i >= 0 {
+		command = command[:i]
+	}
+	return command, fields[1:]
+}
+
+// handleStart links the chat when the deep link carried a code
+func (s *TelegramBotService) handleStart(msg *TelegramInboundMessage, args []string) error {
+	if msg.Chat.Type != "private" {
+		return nil
+	}
+	if len(args) == 0 {
+		return s.reply(msg.Chat.ID, "👋 สวัสดีครับ กรุณาเชื่อมต่อบัญชีผ่านลิงก์จากหน้าโปรไฟล์ในระบบแจ้งซ่อม")
+	}
+
+	user, err := s.Link(args[0], msg.Chat.ID)
+	if errors.Is(err, ErrInvalidLinkCode) {
+		return s.reply(msg.Chat.ID, "❌ ลิงก์เชื่อมต่อไม่ถูกต้องหรือหมดอายุแล้ว กรุณาสร้างลิงก์ใหม่")
+	}
+	if err != nil {
+		return err
+	}
+	return s.reply(msg.Chat.ID, fmt.Sprintf("✅ เชื่อมต่อบัญชี <b>%s</b> สำเร็จ! คุณจะได้รับการแจ้งเตือนงานซ่อมทางแชทนี้", html.EscapeString(user.FullName)))
+}
+
+func (s *TelegramBotService) reply(chatID int64, text string) error {
+	return s.telegram.ForChat(strconv.FormatInt(chatID, 10)).SendMessage(text)
+}
+
+// WebhookSecret returns the secret Telegram must echo on webhook calls
+func (s *TelegramBotService) WebhookSecret() string {
+	return s.settingsService.GetSettingWithDefault(models.SettingTelegramWebhookSecret, "")
+}
+
+// UpdateMode returns how the bot receives updates
+func (s *TelegramBotService) UpdateMode() string {
+	return s.settingsService.GetSettingWithDefault(models.SettingTelegramUpdateMode, TelegramUpdateModeOff)
+}
+
+// StartPolling long-polls getUpdates in a background goroutine while polling mode is on
+func (s *TelegramBotService) StartPolling() {
+	go func() {
+		var offset int64
+		for {
+			if s.UpdateMode() != TelegramUpdateModePolling || !s.telegram.CanSendDirect() {
+				time.Sleep(telegramPollInterval)
+				continue
+			}
+
+			var updates []TelegramUpdate
+			err := s.telegram.callWithTimeout("getUpdates", map[string]interface{}{
+				"offset":          offset,
+				"timeout":         telegramPollTimeout,
+				"allowed_updates": []string{"message", "callback_query"},
+			}, &updates, telegramPollTimeout*time.Second+telegramRequestTimeout)
+			if err != nil {
+				log.Printf("Warning: Failed to poll telegram updates: %v", err)
+				time.Sleep(telegramPollInterval)
+				continue
+			}
+
+			for _, update := range updates {
+				if err := s.HandleUpdate(update); err != nil {
+					log.Printf("Warning: Failed to handle telegram update %d: %v", update.UpdateID, err)
+				}
+				offset = update.UpdateID + 1
+			}
+		}
+	}()
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"repair-system/config"
+	"repair-system/models"
+)
+
+const telegramHelpText = `🤖 <b>คำสั่งสำหรับช่าง</b>
+
+/my - งานที่ได้รับมอบหมายและยังไม่เสร็จ
+/job 123 - ดูรายละเอียดงาน
+/accept 123 - รับงาน
+/status 123 in_progress - เปลี่ยนสถานะ (in_progress, waiting_part, completed, rejected)
+/status 123 rejected เหตุผล - ปฏิเสธงาน (ผู้ดูแลระบบ) ข้อความท้ายสถานะอื่นจะบันทึกเป็นโน้ตภายใน
+/note 123 ข้อความ - บันทึกโน้ตภายใน`
+
+// Button actions carried in callback data as "job:<id>:<action>"
+const (
+	jobActionAccept = "accept"
+	jobCallbackData = "job:%d:%s"
+)
+
+// telegramStatuses are the statuses /status may set. Approval and reopening have their own flows.
+var telegramStatuses = []models.RepairStatus{
+	models.StatusInProgress, models.StatusWaitingPart, models.StatusCompleted, models.StatusRejected,
+}
+
+// JobKeyboard returns the buttons technicians use to move a job along
+func JobKeyboard(requestID uint) *TelegramInlineKeyboard {
+	button := func(text, action string) TelegramInlineButton {
+		return TelegramInlineButton{Text: text, CallbackData: fmt.Sprintf(jobCallbackData, requestID, action)}
+	}
+	return &TelegramInlineKeyboard{InlineKeyboard: [][]TelegramInlineButton{
+		{button("✋ รับงาน", jobActionAccept), button("🔧 เริ่มงาน", string(models.StatusInProgress))},
+		{button("📦 รออะไหล่", string(models.StatusWaitingPart)), button("✅ เสร็จสิ้น", string(models.StatusCompleted))},
+	}}
+}
+
+// handleJobCommand runs a technician command for the user linked to the sender
+func (s *TelegramBotService) handleJobCommand(msg *TelegramInboundMessage, command string, args []string) error {
+	if msg.From == nil {
+		return nil
+	}
+	user, text := s.staffUser(msg.From.ID)
+	if user == nil {
+		return s.reply(msg.Chat.ID, text)
+	}
+
+	if command == "/my" {
+		return s.reply(msg.Chat.ID, s.myJobsText(user))
+	}
+
+	if len(args) == 0 {
+		return s.reply(msg.Chat.ID, telegramHelpText)
+	}
+	request, text := s.loadJob(user, args[0])
+	if request == nil {
+		return s.reply(msg.Chat.ID, text)
+	}
+
+	switch command {
+	case "/job":
+		return s.telegram.ForChat(strconv.FormatInt(msg.Chat.ID, 10)).
+			SendMessageWithKeyboard(s.jobText(request), JobKeyboard(request.ID))
+	case "/accept":
+		return s.reply(msg.Chat.ID, s.applyAction(user, request, jobActionAccept, ""))
+	case "/status":
+		if len(args) < 2 {
+			return s.reply(msg.Chat.ID, "❌ กรุณาระบุสถานะ เช่น /status 123 in_progress")
+		}
+		return s.reply(msg.Chat.ID, s.applyAction(user, request, args[1], strings.Join(args[2:], " ")))
+	case "/note":
+		return s.reply(msg.Chat.ID, s.addNote(user, request, strings.Join(args[1:], " ")))
+	}
+	return nil
+}
+
+// handleCallback runs the action of a pressed job button and answers with the outcome
+func (s *TelegramBotService) handleCallback(query *TelegramCallbackQuery) error {
+	var id uint
+	var action string
+	parts := strings.SplitN(query.Data, ":", 3)
+	if len(parts) == 3 && parts[0] == "job" {
+		if parsed, err := strconv.ParseUint(parts[1], 10, 64); err == nil {
+			id, action = uint(parsed), parts[2]
+		}
+	}
+	if action == "" {
+		return s.answerCallback(query.ID, "❌ ปุ่มนี้ใช้ไม่ได้แล้ว")
+	}
+
+	user, text := s.staffUser(query.From.ID)
+	if user == nil {
+		return s.answerCallback(query.ID, text)
+	}
+	request, text := s.loadJob(user, strconv.FormatUint(uint64(id), 10))
+	if request == nil {
+		return s.answerCallback(query.ID, text)
+	}
+	return s.answerCallback(query.ID, s.applyAction(user, request, action, ""))
+}
+
+func (s *TelegramBotService) answerCallback(queryID, text string) error {
+	if !s.telegram.CanSendDirect() {
+		return nil
+	}
+	return s.telegram.call("answerCallbackQuery", map[string]interface{}{
+		"callback_query_id": queryID,
+		"text":              s.stripTags(text),
+		"show_alert":        strings.HasPrefix(text, "❌"),
+	}, nil)
+}
+
+// staffUser returns the technician or admin linked to the Telegram user, or a message
+// explaining why the command can't be used
+func (s *TelegramBotService) staffUser(telegramUserID int64) (*models.User, string) {
+	var user models.User
+	err := config.DB.Where("telegram_id = ?", strconv.FormatInt(telegramUserID, 10)).First(&user).Error
+	if err != nil {
+		return nil, "❌ ยังไม่ได้เชื่อมต่อบัญชี กรุณาเชื่อมต่อผ่านลิงก์จากระบบแจ้งซ่อมก่อน"
+	}
+	if user.Role != models.RoleTechnician && user.Role != models.RoleAdmin {
+		return nil, "❌ คำสั่งนี้สำหรับช่างและผู้ดูแลระบบเท่านั้น"
+	}
+	// Same rule as the MaintenanceMode middleware: only admins keep working
+	if user.Role != models.RoleAdmin {
+		if status := NewMaintenanceService(s.settingsService).Status(); status.Enabled {
+			return nil, "🛠 " + html.EscapeString(status.Message)
+		}
+	}
+	return &user, ""
+}
+
+// loadJob finds a request the user may see, exactly as the REST API would
+func (s *TelegramBotService) loadJob(user *models.User, idText string) (*models.RepairRequest, string) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(idText, "#"), 10, 64)
+	if err != nil {
+		return nil, "❌ เลขที่งานไม่ถูกต้อง"
+	}
+
+	var request models.RepairRequest
+	err = config.DB.Scopes(VisibleRepairRequests(*user)).
+		Preload("Category").Preload("Requester").Preload("Technician").
+		First(&request, id).Error
+	if err != nil {
+		return nil, fmt.Sprintf("❌ ไม่พบงาน #%d", id)
+	}
+	return &request, ""
+}
+
+// applyAction claims the job or moves it to a new status through the same update path
+// as PUT /api/repair-requests/:id and returns the reply text
+func (s *TelegramBotService) applyAction(user *models.User, request *models.RepairRequest, action, reason string) string {
+	before := *request
+
+	if action == jobActionAccept {
+		if request.TechnicianID != nil && *request.TechnicianID == user.ID {
+			return fmt.Sprintf("ℹ️ คุณรับงาน #%d อยู่แล้ว", request.ID)
+		}
+		request.TechnicianID = &user.ID
+	} else {
+		status := models.RepairStatus(action)
+		if !isTelegramStatus(status) {
+			names := make([]string, len(telegramStatuses))
+			for i, allowed := range telegramStatuses {
+				names[i] = string(allowed)
+			}
+			return "❌ สถานะไม่ถูกต้อง ใช้ได้: " + strings.Join(names, ", ")
+		}
+		request.Status = status
+		if status == models.StatusRejected {
+			request.RejectionReason = reason
+			reason = ""
+		}
+	}
+
+	err := s.repairRequestService.Update(request, &before, *user)
+	var transitionErr *TransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		if len(transitionErr.Missing) > 0 {
+			return fmt.Sprintf("❌ ต้องระบุข้อมูลเพิ่ม: %s (ใช้ /accept %d เพื่อรับงานก่อน)", strings.Join(transitionErr.Missing, ", "), request.ID)
+		}
+		allowed := []string{}
+		for _, status := range transitionErr.Allowed {
+			allowed = append(allowed, statusLabel(string(status)))
+		}
+		return fmt.Sprintf("❌ เปลี่ยนสถานะจาก %s เป็น %s ไม่ได้ (ทำได้: %s)",
+			statusLabel(string(transitionErr.From)), statusLabel(string(transitionErr.To)), strings.Join(allowed, ", "))
+	case errors.Is(err, ErrVersionConflict):
+		return "❌ งานนี้เพิ่งถูกแก้ไข กรุณาลองใหม่อีกครั้ง"
+	case err != nil:
+		return "❌ อัปเดตงานไม่สำเร็จ"
+	}
+
+	if action == jobActionAccept {
+		return fmt.Sprintf("✅ รับงาน #%d แล้ว", request.ID)
+	}
+	reply := fmt.Sprintf("✅ งาน #%d: %s", request.ID, statusLabel(string(request.Status)))
+	// Text after any other status is kept, but only where staff can see it
+	if strings.TrimSpace(reason) != "" {
+		reply += "\n" + s.addNote(user, request, reason)
+	}
+	return reply
+}
+
+func isTelegramStatus(status models.RepairStatus) bool {
+	for _, allowed := range telegramStatuses {
+		if status == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// addNote stores an internal note, which only staff can see
+func (s *TelegramBotService) addNote(user *models.User, request *models.RepairRequest, content string) string {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Sprintf("❌ กรุณาระบุข้อความ เช่น /note %d เปลี่ยนอะไหล่แล้ว", request.ID)
+	}
+
+	comment := models.Comment{
+		RepairRequestID: request.ID,
+		UserID:          user.ID,
+		Content:         content,
+		IsInternal:      true,
+	}
+	if err := config.DB.Create(&comment).Error; err != nil {
+		return "❌ บันทึกโน้ตไม่สำเร็จ"
+	}
+	return fmt.Sprintf("📝 บันทึกโน้ตในงาน #%d แล้ว", request.ID)
+}
+
+// myJobsText lists the user's open jobs, most urgent first
+func (s *TelegramBotService) myJobsText(user *models.User) string {
+	page, err := s.queryService.List(config.DB, *user, RepairRequestFilter{
+		Statuses:     []models.RepairStatus{models.StatusPending, models.StatusInProgress, models.StatusWaitingPart},
+		TechnicianID: &user.ID,
+		Sort:         "priority",
+		Descending:   true,
+		Limit:        20,
+	})
+	if err != nil {
+		return "❌ โหลดรายการงานไม่สำเร็จ"
+	}
+	if len(page.Data) == 0 {
+		return "🎉 ไม่มีงานค้าง"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "📋 <b>งานของคุณ (%d)</b>\n", page.Pagination.Total)
+	for _, request := range page.Data {
+		fmt.Fprintf(&b, "\n#%d %s\n   %s · %s", request.ID, html.EscapeString(request.Title),
+			statusLabel(string(request.Status)), priorityLabel(string(request.Priority)))
+	}
+	b.WriteString("\n\nดูรายละเอียดด้วย /job เลขที่งาน")
+	return b.String()
+}
+
+func (s *TelegramBotService) jobText(request *models.RepairRequest) string {
+	technician := "ยังไม่ได้มอบหมาย"
+	if request.Technician != nil {
+		technician = request.Technician.FullName
+	}
+	location := request.Location
+	if location == "" {
+		location = "ไม่ระบุ"
+	}
+
+	return fmt.Sprintf(`📋 <b>งาน #%d: %s</b>
+
+• สถานะ: %s
+• ความสำคัญ: %s
+• หมวดหมู่: %s
+• สถานที่: %s
+• ผู้แจ้ง: %s
+• ช่าง: %s
+
+%s`,
+		request.ID, html.EscapeString(request.Title),
+		statusLabel(string(request.Status)),
+		priorityLabel(string(request.Priority)),
+		html.EscapeString(request.Category.Name),
+		html.EscapeString(location),
+		html.EscapeString(request.Requester.FullName),
+		html.EscapeString(technician),
+		html.EscapeString(request.Description))
+}
+
+// stripTags removes the HTML markup that callback answers can't display
+func (s *TelegramBotService) stripTags(text string) string {
+	return strings.NewReplacer("<b>", "", "</b>", "").Replace(text)
+}
@@ -0,0 +1,118 @@
+package services
+
+import "repair-system/models"
+
+// TelegramDirectService sends private Telegram messages to the people a repair request
+// concerns: the assigned technician and the requester. The group chat keeps receiving
+// every event through TelegramService. It implements Notifier.
+type TelegramDirectService struct {
+	telegram *TelegramService
+}
+
+func NewTelegramDirectService(settingsService *SettingsService) *TelegramDirectService {
+	return &TelegramDirectService{
+		telegram: NewTelegramServiceWithSettings(settingsService),
+	}
+}
+
+// Channel implements Notifier
+func (s *TelegramDirectService) Channel() string {
+	return "telegram_direct"
+}
+
+func (s *TelegramDirectService) IsEnabled() bool {
+	return s.telegram.CanSendDirect()
+}
+
+// Audiences implements Notifier
+func (s *TelegramDirectService) Audiences(event string) []string {
+	switch event {
+	case models.EventRequestCreated:
+		return nil
+	case models.EventRequestAssigned, models.EventRequestReopened, models.EventRequestSignedOff:
+		return []string{NotificationAudienceTechnician}
+	case models.EventCommentAdded:
+		return []string{NotificationAudienceRequester, NotificationAudienceTechnician}
+	default:
+		return []string{NotificationAudienceRequester}
+	}
+}
+
+// NotifyNewRepairRequest is only announced to the group
+func (s *TelegramDirectService) NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error {
+	return nil
+}
+
+// NotifyStatusChange tells the requester. Completion and rejection have their own messages.
+func (s *TelegramDirectService) NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error {
+	if request.Status == models.StatusCompleted || request.Status == models.StatusRejected {
+		return nil
+	}
+	if chat := s.chatFor(&request.Requester); chat != nil {
+		return chat.NotifyStatusChange(request, oldStatus, technician)
+	}
+	return nil
+}
+
+// NotifyAssignment tells the technician who got the job
+func (s *TelegramDirectService) NotifyAssignment(request *models.RepairRequest, technician *models.User) error {
+	if chat := s.chatFor(technician); chat != nil {
+		return chat.NotifyAssignment(request, technician)
+	}
+	return nil
+}
+
+func (s *TelegramDirectService) NotifyCompletion(request *models.RepairRequest, technician *models.User) error {
+	if chat := s.chatFor(&request.Requester); chat != nil {
+		return chat.NotifyCompletion(request, technician)
+	}
+	return nil
+}
+
+func (s *TelegramDirectService) NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error {
+	if chat := s.chatFor(&request.Requester); chat != nil {
+		return chat.NotifyRejection(request, reason, admin)
+	}
+	return nil
+}
+
+// NotifyNewComment implements CommentNotifier
+func (s *TelegramDirectService) NotifyNewComment(request *models.RepairRequest, comment *models.Comment, author *models.User, recipient *models.User) error {
+	if chat := s.chatFor(recipient); chat != nil {
+		return chat.NotifyNewComment(request, comment, author, recipient)
+	}
+	return nil
+}
+
+// NotifyApproval implements ApprovalNotifier
+func (s *TelegramDirectService) NotifyApproval(request *models.RepairRequest, approval *models.Approval, oldStatus string) error {
+	if chat := s.chatFor(&request.Requester); chat != nil {
+		return chat.NotifyApproval(request, approval, oldStatus)
+	}
+	return nil
+}
+
+// NotifyReopen implements ReopenNotifier. Only the technician who did the job is told.
+func (s *TelegramDirectService) NotifyReopen(request *models.RepairRequest, technician *models.User, reason string) error {
+	if chat := s.chatFor(technician); chat != nil {
+		return chat.NotifyReopen(request, technician, reason)
+	}
+	return nil
+}
+
+// NotifySignOff implements SignOffNotifier. The technician hears how their job was received.
+func (s *TelegramDirectService) NotifySignOff(request *models.RepairRequest, technician *models.User, requester *models.User) error {
+	if chat := s.chatFor(technician); chat != nil {
+		return chat.NotifySignOff(request, technician, requester)
+	}
+	return nil
+}
+
+// chatFor returns a service bound to the user's private chat, or nil when the user
+// has no linked chat or has opted out
+func (s *TelegramDirectService) chatFor(user *models.User) *TelegramService {
+	if user == nil || user.TelegramID == "" || !user.TelegramDMs {
+		return nil
+	}
+	return s.telegram.ForChat(user.TelegramID)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBotToken = "123456:SECRET-token"
+
+// newTestTelegramService points a service at handler. Without a settings service the
+// fields set here are never refreshed from the database.
+func newTestTelegramService(t *testing.T, handler http.HandlerFunc) (*TelegramService, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &TelegramService{
+		BotToken:   testBotToken,
+		ChatID:     "-100200",
+		Enabled:    true,
+		APIBaseURL: server.URL + "/",
+		HTTPClient: server.Client(),
+	}, server
+}
+
+func TestSendMessage(t *testing.T) {
+	var path string
+	var sent TelegramMessage
+	service, _ := newTestTelegramService(t, func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+	})
+
+	keyboard := JobKeyboard(7)
+	if err := service.SendMessageWithKeyboard("<b>งานใหม่</b>", keyboard); err != nil {
+		t.Fatalf("SendMessageWithKeyboard: %v", err)
+	}
+	if path != "/bot"+testBotToken+"/sendMessage" {
+		t.Errorf("path = %q", path)
+	}
+	if sent.ChatID != "-100200" || sent.Text != "<b>งานใหม่</b>" || sent.ParseMode != "HTML" {
+		t.Errorf("sent %+v", sent)
+	}
+	if sent.ReplyMarkup == nil || len(sent.ReplyMarkup.InlineKeyboard) != len(keyboard.InlineKeyboard) {
+		t.Errorf("reply markup = %+v, want %+v", sent.ReplyMarkup, keyboard)
+	}
+}
+
+func TestSendMessageRateLimited(t *testing.T) {
+	service, _ := newTestTelegramService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 17","parameters":{"retry_after":17}}`))
+	})
+
+	err := service.SendMessage("hello")
+	var rateErr *TelegramRateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("SendMessage = %v, want TelegramRateLimitError", err)
+	}
+	if rateErr.Seconds != 17 || rateErr.Description != "Too Many Requests: retry after 17" {
+		t.Errorf("rate limit error = %+v", rateErr)
+	}
+
+	// The outbox reads the delay through RetryAfterError
+	var retryErr RetryAfterError
+	if !errors.As(err, &retryErr) || retryErr.RetryAfter() != 17*time.Second {
+		t.Errorf("RetryAfter = %v", retryErr)
+	}
+}
+
+func TestSendMessageAPIError(t *testing.T) {
+	service, _ := newTestTelegramService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-100300}}`))
+	})
+
+	err := service.SendMessage("hello")
+	var apiErr *TelegramAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("SendMessage = %v, want TelegramAPIError", err)
+	}
+	if apiErr.Method != "sendMessage" || apiErr.StatusCode != http.StatusBadRequest || apiErr.MigrateToChatID != -100300 {
+		t.Errorf("API error = %+v", apiErr)
+	}
+}
+
+func TestTelegramErrorsHideBotToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		hang  bool // Never answer, so the client times out
+		setup func(service *TelegramService, server *httptest.Server)
+	}{
+		{"connection refused", false, func(service *TelegramService, server *httptest.Server) {
+			server.Close()
+		}},
+		{"timeout", true, func(service *TelegramService, server *httptest.Server) {
+			service.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+		}},
+		{"API error", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			release := make(chan struct{})
+			service, server := newTestTelegramService(t, func(w http.ResponseWriter, r *http.Request) {
+				if tt.hang {
+					<-release
+				}
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+			})
+			defer close(release)
+			if tt.setup != nil {
+				tt.setup(service, server)
+			}
+
+			err := service.SendMessage("hello")
+			if err == nil {
+				t.Fatal("SendMessage succeeded")
+			}
+			if strings.Contains(err.Error(), testBotToken) || strings.Contains(err.Error(), "SECRET") {
+				t.Errorf("error leaks the bot token: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"repair-system/config"
+	"repair-system/models"
+
+	"gorm.io/gorm"
+)
+
+// Headers sent with every webhook delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret, prefixed with "sha256=".
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	webhookTimeout         = 10 * time.Second
+	webhookMaxAttempts     = 8
+	webhookMaxResponseBody = 1024
+)
+
+// ErrWebhookUnavailable is returned when a delivery's webhook was deleted or turned off
+var ErrWebhookUnavailable = errors.New("webhook is no longer active")
+
+// WebhookEvents lists the events a webhook can subscribe to
+var WebhookEvents = NotificationEvents
+
+// WebhookPayload is the JSON body of a delivery
+type WebhookPayload struct {
+	ID         string      `json:"id"` // Shared by every delivery of the same event
+	Event      string      `json:"event"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       WebhookData `json:"data"`
+}
+
+type WebhookData struct {
+	RepairRequest WebhookRepairRequest `json:"repairRequest"`
+	Actor         *WebhookUser         `json:"actor,omitempty"`
+	OldStatus     string               `json:"oldStatus,omitempty"`
+	Reason        string               `json:"reason,omitempty"`
+	Comment       *WebhookComment      `json:"comment,omitempty"`
+	Approval      *WebhookApproval     `json:"approval,omitempty"`
+	SignOff       *WebhookSignOff      `json:"signOff,omitempty"`
+}
+
+// WebhookRepairRequest is the public view of a repair request sent to webhooks
+type WebhookRepairRequest struct {
+	ID              uint                  `json:"id"`
+	Title           string                `json:"title"`
+	Description     string                `json:"description"`
+	Location        string                `json:"location"`
+	Status          models.RepairStatus   `json:"status"`
+	Priority        models.RepairPriority `json:"priority"`
+	Category        string                `json:"category"`
+	CategoryID      uint                  `json:"categoryId"`
+	Requester       WebhookUser           `json:"requester"`
+	Technician      *WebhookUser          `json:"technician"`
+	Cost            float64               `json:"cost"`
+	CreatedAt       time.Time             `json:"createdAt"`
+	UpdatedAt       time.Time             `json:"updatedAt"`
+	CompletedAt     *time.Time            `json:"completedAt"`
+	RejectionReason string                `json:"rejectionReason,omitempty"`
+	ReopenCount     int                   `json:"reopenCount"`
+}
+
+type WebhookUser struct {
+	ID       uint            `json:"id"`
+	FullName string          `json:"fullName"`
+	Role     models.UserRole `json:"role"`
+}
+
+type WebhookComment struct {
+	ID      uint   `json:"id"`
+	Content string `json:"content"`
+}
+
+type WebhookApproval struct {
+	Approved bool   `json:"approved"`
+	Note     string `json:"note"`
+}
+
+type WebhookSignOff struct {
+	Status  models.SignOffStatus `json:"status"`
+	Rating  *int                 `json:"rating,omitempty"`
+	Comment string               `json:"comment,omitempty"` // The rating comment or the dispute reason
+}
+
+// webhookHTTPError is returned when an endpoint answers with a non-2xx status
+type webhookHTTPError struct {
+	StatusCode int
+	retryAfter time.Duration
+}
+
+func (e *webhookHTTPError) Error() string {
+	return fmt.Sprintf("endpoint returned status %d", e.StatusCode)
+}
+
+// RetryAfter implements RetryAfterError
+func (e *webhookHTTPError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// WebhookService records and delivers webhook events. It implements Notifier, so events
+// reach it through the notification outbox; each subscribed endpoint then gets its own
+// delivery with independent retries.
+type WebhookService struct {
+	encryption *EncryptionService
+	client     *http.Client
+}
+
+func NewWebhookService() *WebhookService {
+	return &WebhookService{
+		encryption: NewEncryptionService(),
+		client:     &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Channel implements Notifier
+func (s *WebhookService) Channel() string {
+	return "webhook"
+}
+
+// IsEnabled reports whether any webhook is active
+func (s *WebhookService) IsEnabled() bool {
+	var count int64
+	config.DB.Model(&models.Webhook{}).Where("active = ?", true).Count(&count)
+	return count > 0
+}
+
+// IsValidWebhookEvent reports whether webhooks can subscribe to the event
+func IsValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhookURL checks that the URL is an absolute http or https URL
+func ValidateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return errors.New("URL must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// GenerateSecret returns a random signing secret
+func (s *WebhookService) GenerateSecret() (string, error) {
+	return randomHex(32)
+}
+
+// SetSecret encrypts the secret and stores it on the webhook
+func (s *WebhookService) SetSecret(webhook *models.Webhook, secret string) error {
+	encrypted, err := s.encryption.Encrypt(secret)
+	if err != nil {
+		return err
+	}
+	webhook.Secret = encrypted
+	return nil
+}
+
+// Sign returns the signature header value for a body sent at timestamp
+func (s *WebhookService) Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Audiences implements Notifier
+func (s *WebhookService) Audiences(event string) []string {
+	return []string{NotificationAudienceSubscribers}
+}
+
+func (s *WebhookService) NotifyNewRepairRequest(request *models.RepairRequest, requester *models.User) error {
+	return s.publish(models.EventRequestCreated, request, WebhookData{Actor: webhookUser(requester)})
+}
+
+func (s *WebhookService) NotifyStatusChange(request *models.RepairRequest, oldStatus string, technician *models.User) error {
+	return s.publish(models.EventRequestStatusChanged, request, WebhookData{OldStatus: oldStatus})
+}
+
+func (s *WebhookService) NotifyAssignment(request *models.RepairRequest, technician *models.User) error {
+	return s.publish(models.EventRequestAssigned, request, WebhookData{})
+}
+
+func (s *WebhookService) NotifyCompletion(request *models.RepairRequest, technician *models.User) error {
+	return s.publish(models.EventRequestCompleted, request, WebhookData{})
+}
+
+func (s *WebhookService) NotifyRejection(request *models.RepairRequest, reason string, admin *models.User) error {
+	return s.publish(models.EventRequestRejected, request, WebhookData{Actor: webhookUser(admin), Reason: reason})
+}
+
+// NotifyNewComment implements CommentNotifier
+func (s *WebhookService) NotifyNewComment(request *models.RepairRequest, comment *models.Comment, author *models.User, recipient *models.User) error {
+	return s.publish(models.EventCommentAdded, request, WebhookData{
+		Actor:   webhookUser(author),
+		Comment: &WebhookComment{ID: comment.ID, Content: comment.Content},
+	})
+}
+
+// NotifyApproval implements ApprovalNotifier
+func (s *WebhookService) NotifyApproval(request *models.RepairRequest, approval *models.Approval, oldStatus string) error {
+	return s.publish(models.EventRequestApproval, request, WebhookData{
+		Actor:     webhookUser(&approval.Approver),
+		OldStatus: oldStatus,
+		Approval:  &WebhookApproval{Approved: approval.Approved, Note: approval.Note},
+	})
+}
+
+// NotifyReopen implements ReopenNotifier
+func (s *WebhookService) NotifyReopen(request *models.RepairRequest, technician *models.User, reason string) error {
+	return s.publish(models.EventRequestReopened, request, WebhookData{
+		Actor:     webhookUser(&request.Requester),
+		OldStatus: string(models.StatusCompleted),
+		Reason:    reason,
+	})
+}
+
+// NotifySignOff implements SignOffNotifier
+func (s *WebhookService) NotifySignOff(request *models.RepairRequest, technician *models.User, requester *models.User) error {
+	comment := request.RatingComment
+	if request.SignOff == models.SignOffDisputed {
+		comment = request.DisputeReason
+	}
+	return s.publish(models.EventRequestSignedOff, request, WebhookData{
+		Actor:   webhookUser(requester),
+		SignOff: &WebhookSignOff{Status: request.SignOff, Rating: request.Rating, Comment: comment},
+	})
+}
+
+// publish records a pending delivery for every active webhook subscribed to the event
+func (s *WebhookService) publish(event string, request *models.RepairRequest, data WebhookData) error {
+	var webhooks []models.Webhook
+	if err := config.DB.Where("active = ?", true).Find(&webhooks).Error; err != nil {
+		return err
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return err
+	}
+	data.RepairRequest = webhookRepairRequest(request)
+	body, err := json.Marshal(WebhookPayload{ID: id, Event: event, OccurredAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, webhook := range webhooks {
+			if !webhook.Subscribes(event) {
+				continue
+			}
+			delivery := models.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				Event:         event,
+				Payload:       string(body),
+				Status:        models.DeliveryPending,
+				NextAttemptAt: time.Now(),
+			}
+			if err := tx.Create(&delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CancelPending cancels the webhook's deliveries that have not been sent yet
+func (s *WebhookService) CancelPending(tx *gorm.DB, webhookID uint) error {
+	return tx.Model(&models.WebhookDelivery{}).
+		Where("webhook_id = ? AND status = ?", webhookID, models.DeliveryPending).
+		Updates(map[string]interface{}{"status": models.DeliveryCancelled, "last_error": "webhook was deleted"}).Error
+}
+
+// Start delivers due webhook events every interval in a background goroutine
+func (s *WebhookService) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := s.ProcessDue(); err != nil {
+				log.Printf("Warning: Failed to process webhook deliveries: %v", err)
+			}
+		}
+	}()
+}
+
+// ProcessDue sends the pending deliveries whose next attempt is due
+func (s *WebhookService) ProcessDue() error {
+	var deliveries []models.WebhookDelivery
+	err := config.DB.Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(outboxBatchSize).
+		Find(&deliveries).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		s.deliver(&deliveries[i])
+	}
+	return nil
+}
+
+// Redeliver queues a delivery to be sent again straight away
+func (s *WebhookService) Redeliver(delivery *models.WebhookDelivery) error {
+	delivery.Status = models.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	return config.DB.Save(delivery).Error
+}
+
+func (s *WebhookService) deliver(delivery *models.WebhookDelivery) {
+	err := s.send(delivery)
+	if errors.Is(err, ErrWebhookUnavailable) {
+		delivery.Status = models.DeliveryCancelled
+		delivery.LastError = err.Error()
+		if err := config.DB.Save(delivery).Error; err != nil {
+			log.Printf("Warning: Failed to update webhook delivery %d: %v", delivery.ID, err)
+		}
+		return
+	}
+	delivery.Attempts++
+
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= webhookMaxAttempts {
+			delivery.Status = models.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = time.Now().Add(retryDelay(delivery.Attempts, err))
+		}
+	}
+
+	if err := config.DB.Save(delivery).Error; err != nil {
+		log.Printf("Warning: Failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send signs and posts the delivery, recording the endpoint's response
+func (s *WebhookService) send(delivery *models.WebhookDelivery) error {
+	var webhook models.Webhook
+	if err := config.DB.First(&webhook, delivery.WebhookID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: webhook %d was deleted", ErrWebhookUnavailable, delivery.WebhookID)
+		}
+		return fmt.Errorf("webhook %d: %v", delivery.WebhookID, err)
+	}
+	if !webhook.Active {
+		return fmt.Errorf("%w: webhook %d is inactive", ErrWebhookUnavailable, delivery.WebhookID)
+	}
+	secret, err := s.encryption.Decrypt(webhook.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt webhook secret: %v", err)
+	}
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RepairSystem-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, s.Sign(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		delivery.ResponseStatus = 0
+		delivery.ResponseBody = ""
+		return err
+	}
+	defer resp.Body.Close()
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxResponseBody))
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = string(responseBody)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		httpErr := &webhookHTTPError{StatusCode: resp.StatusCode}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			httpErr.retryAfter = time.Duration(seconds) * time.Second
+		}
+		return httpErr
+	}
+	return nil
+}
+
+func webhookRepairRequest(request *models.RepairRequest) WebhookRepairRequest {
+	return WebhookRepairRequest{
+		ID:              request.ID,
+		Title:           request.Title,
+		Description:     request.Description,
+		Location:        request.Location,
+		Status:          request.Status,
+		Priority:        request.Priority,
+		Category:        request.Category.Name,
+		CategoryID:      request.CategoryID,
+		Requester:       *webhookUser(&request.Requester),
+		Technician:      webhookUser(request.Technician),
+		Cost:            request.Cost,
+		CreatedAt:       request.CreatedAt,
+		UpdatedAt:       request.UpdatedAt,
+		CompletedAt:     request.CompletedAt,
+		RejectionReason: request.RejectionReason,
+		ReopenCount:     request.ReopenCount,
+	}
+}
+
+func webhookUser(user *models.User) *WebhookUser {
+	if user == nil {
+		return nil
+	}
+	return &WebhookUser{ID: user.ID, FullName: user.FullName, Role: user.Role}
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"repair-system/models"
+)
+
+// StatusTransition describes a single allowed edge in the repair request status graph
+type StatusTransition struct {
+	From           models.RepairStatus `json:"from"`
+	To             models.RepairStatus `json:"to"`
+	Roles          []models.UserRole   `json:"roles"`
+	RequiredFields []string            `json:"requiredFields"`
+	// Approval edges are taken by deciding the approval, never by editing the request.
+	// The category's approver may take them whatever their role.
+	Approval bool `json:"approval,omitempty"`
+}
+
+// repairStatusTransitions is the full status graph. Any edge not listed here is rejected.
+var repairStatusTransitions = []StatusTransition{
+	{From: models.StatusAwaitingApproval, To: models.StatusPending, Roles: []models.UserRole{models.RoleAdmin}, RequiredFields: []string{}, Approval: true},
+	{From: models.StatusAwaitingApproval, To: models.StatusRejected, Roles: []models.UserRole{models.RoleAdmin}, RequiredFields: []string{"rejectionReason"}, Approval: true},
+	{From: models.StatusPending, To: models.StatusInProgress, Roles: []models.UserRole{models.RoleAdmin, models.RoleTechnician}, RequiredFields: []string{"technicianId"}},
+	{From: models.StatusPending, To: models.StatusRejected, Roles: []models.UserRole{models.RoleAdmin}, RequiredFields: []string{"rejectionReason"}},
+	{From: models.StatusInProgress, To: models.StatusWaitingPart, Roles: []models.UserRole{models.RoleAdmin, models.RoleTechnician}, RequiredFields: []string{"technicianId"}},
+	{From: models.StatusInProgress, To: models.StatusCompleted, Roles: []models.UserRole{models.RoleAdmin, models.RoleTechnician}, RequiredFields: []string{"technicianId"}},
+	{From: models.StatusInProgress, To: models.StatusRejected, Roles: []models.UserRole{models.RoleAdmin}, RequiredFields: []string{"rejectionReason"}},
+	{From: models.StatusWaitingPart, To: models.StatusInProgress, Roles: []models.UserRole{models.RoleAdmin, models.RoleTechnician}, RequiredFields: []string{"technicianId"}},
+	{From: models.StatusWaitingPart, To: models.StatusRejected, Roles: []models.UserRole{models.RoleAdmin}, RequiredFields: []string{"rejectionReason"}},
+	// Appeals send a rejected request back to the approvers
+	{From: models.StatusRejected, To: models.StatusAwaitingApproval, Roles: []models.UserRole{models.RoleRequester}, RequiredFields: []string{"appealReason"}},
+	// Reopening puts a completed job back in the queue, still with its last technician
+	{From: models.StatusCompleted, To: models.StatusPending, Roles: []models.UserRole{models.RoleRequester}, RequiredFields: []string{"reopenReason"}},
+}
+
+// statusRequiredFields lists what a request must keep while it stays in a status, so an
+// edit that leaves the status alone cannot clear a field the transition required
+var statusRequiredFields = map[models.RepairStatus][]string{
+	models.StatusInProgress:  {"technicianId"},
+	models.StatusWaitingPart: {"technicianId"},
+	models.StatusCompleted:   {"technicianId", "completedAt"},
+	models.StatusRejected:    {"rejectionReason"},
+}
+
+// TransitionError is returned when a status change is not permitted.
+// Missing is set when the edge exists but required fields are not filled in.
+type TransitionError struct {
+	From    models.RepairStatus   `json:"from"`
+	To      models.RepairStatus   `json:"to"`
+	Allowed []models.RepairStatus `json:"allowed"`
+	Missing []string              `json:"missing,omitempty"`
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Missing) > 0 {
+		return fmt.Sprintf("transition from %s to %s requires: %s", e.From, e.To, strings.Join(e.Missing, ", "))
+	}
+	return fmt.Sprintf("transition from %s to %s is not allowed", e.From, e.To)
+}
+
+type WorkflowService struct{}
+
+func NewWorkflowService() *WorkflowService {
+	return &WorkflowService{}
+}
+
+// AllowedTransitions returns the edges leaving the given status that the role may take.
+// approver says whether the user approves the request's category.
+func (s *WorkflowService) AllowedTransitions(from models.RepairStatus, role models.UserRole, approver bool) []StatusTransition {
+	allowed := []StatusTransition{}
+	for _, t := range repairStatusTransitions {
+		if t.From == from && (s.hasRole(t.Roles, role) || (t.Approval && approver)) {
+			allowed = append(allowed, t)
+		}
+	}
+	return allowed
+}
+
+// ValidateTransition checks that the request may move from its previous status to its
+// current one. The request must already carry the updated field values. Approval edges
+// are left to ValidateApproval.
+func (s *WorkflowService) ValidateTransition(from models.RepairStatus, request *models.RepairRequest, role models.UserRole) error {
+	return s.validate(from, request, s.filter(s.AllowedTransitions(from, role, false), false))
+}
+
+// ValidateApproval checks an approval decision against the approval edges
+func (s *WorkflowService) ValidateApproval(from models.RepairStatus, request *models.RepairRequest, role models.UserRole, approver bool) error {
+	return s.validate(from, request, s.filter(s.AllowedTransitions(from, role, approver), true))
+}
+
+// filter keeps the approval edges, or all the others
+func (s *WorkflowService) filter(transitions []StatusTransition, approval bool) []StatusTransition {
+	kept := []StatusTransition{}
+	for _, t := range transitions {
+		if t.Approval == approval {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+func (s *WorkflowService) validate(from models.RepairStatus, request *models.RepairRequest, allowed []StatusTransition) error {
+	to := request.Status
+	if from == to {
+		return nil
+	}
+
+	transitionErr := &TransitionError{From: from, To: to, Allowed: []models.RepairStatus{}}
+	for _, t := range allowed {
+		transitionErr.Allowed = append(transitionErr.Allowed, t.To)
+	}
+
+	for _, t := range allowed {
+		if t.To != to {
+			continue
+		}
+		for _, field := range t.RequiredFields {
+			if !s.hasField(request, field) {
+				transitionErr.Missing = append(transitionErr.Missing, field)
+			}
+		}
+		if len(transitionErr.Missing) > 0 {
+			return transitionErr
+		}
+		return nil
+	}
+
+	return transitionErr
+}
+
+// ValidateStatusFields checks that the request still has every field its current status requires
+func (s *WorkflowService) ValidateStatusFields(request *models.RepairRequest) error {
+	missing := []string{}
+	for _, field := range statusRequiredFields[request.Status] {
+		if !s.hasField(request, field) {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return &TransitionError{From: request.Status, To: request.Status, Allowed: []models.RepairStatus{}, Missing: missing}
+	}
+	return nil
+}
+
+func (s *WorkflowService) hasRole(roles []models.UserRole, role models.UserRole) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WorkflowService) hasField(request *models.RepairRequest, field string) bool {
+	switch field {
+	case "technicianId":
+		return request.TechnicianID != nil
+	case "rejectionReason":
+		return strings.TrimSpace(request.RejectionReason) != ""
+	case "appealReason":
+		return strings.TrimSpace(request.AppealReason) != ""
+	case "reopenReason":
+		return strings.TrimSpace(request.ReopenReason) != ""
+	case "completedAt":
+		return request.CompletedAt != nil
+	default:
+		return false
+	}
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"repair-system/models"
+)
+
+func TestValidateTransition(t *testing.T) {
+	technicianID := uint(2)
+	now := time.Now()
+	tests := []struct {
+		name        string
+		from        models.RepairStatus
+		request     models.RepairRequest
+		role        models.UserRole
+		wantMissing []string
+		wantBlocked bool // The edge is not open to the role at all
+	}{
+		{"unchanged status", models.StatusPending, models.RepairRequest{Status: models.StatusPending}, models.RoleRequester, nil, false},
+		{"start with technician", models.StatusPending, models.RepairRequest{Status: models.StatusInProgress, TechnicianID: &technicianID}, models.RoleTechnician, nil, false},
+		{"start without technician", models.StatusPending, models.RepairRequest{Status: models.StatusInProgress}, models.RoleAdmin, []string{"technicianId"}, false},
+		{"requester cannot start", models.StatusPending, models.RepairRequest{Status: models.StatusInProgress, TechnicianID: &technicianID}, models.RoleRequester, nil, true},
+		{"complete", models.StatusInProgress, models.RepairRequest{Status: models.StatusCompleted, TechnicianID: &technicianID, CompletedAt: &now}, models.RoleTechnician, nil, false},
+		{"skip in progress", models.StatusPending, models.RepairRequest{Status: models.StatusCompleted, TechnicianID: &technicianID}, models.RoleAdmin, nil, true},
+		{"reject with reason", models.StatusWaitingPart, models.RepairRequest{Status: models.StatusRejected, RejectionReason: "No parts"}, models.RoleAdmin, nil, false},
+		{"reject with blank reason", models.StatusWaitingPart, models.RepairRequest{Status: models.StatusRejected, RejectionReason: "  "}, models.RoleAdmin, []string{"rejectionReason"}, false},
+		{"technician cannot reject", models.StatusPending, models.RepairRequest{Status: models.StatusRejected, RejectionReason: "No"}, models.RoleTechnician, nil, true},
+		{"appeal", models.StatusRejected, models.RepairRequest{Status: models.StatusAwaitingApproval, AppealReason: "Still broken"}, models.RoleRequester, nil, false},
+		{"appeal without reason", models.StatusRejected, models.RepairRequest{Status: models.StatusAwaitingApproval}, models.RoleRequester, []string{"appealReason"}, false},
+		{"reopen", models.StatusCompleted, models.RepairRequest{Status: models.StatusPending, ReopenReason: "Leaking again"}, models.RoleRequester, nil, false},
+		{"admin cannot reopen", models.StatusCompleted, models.RepairRequest{Status: models.StatusPending, ReopenReason: "Leaking again"}, models.RoleAdmin, nil, true},
+		// Approval edges are only taken through ValidateApproval
+		{"approve by editing", models.StatusAwaitingApproval, models.RepairRequest{Status: models.StatusPending}, models.RoleAdmin, nil, true},
+	}
+	service := NewWorkflowService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateTransition(tt.from, &tt.request, tt.role)
+			if tt.wantMissing == nil && !tt.wantBlocked {
+				if err != nil {
+					t.Fatalf("ValidateTransition = %v, want nil", err)
+				}
+				return
+			}
+
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("ValidateTransition = %v, want TransitionError", err)
+			}
+			if transitionErr.From != tt.from || transitionErr.To != tt.request.Status {
+				t.Errorf("error edge = %s -> %s", transitionErr.From, transitionErr.To)
+			}
+			if !reflect.DeepEqual(transitionErr.Missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", transitionErr.Missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestValidateApproval(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  models.RepairRequest
+		role     models.UserRole
+		approver bool
+		wantErr  bool
+	}{
+		{"admin approves", models.RepairRequest{Status: models.StatusPending}, models.RoleAdmin, false, false},
+		{"approver technician approves", models.RepairRequest{Status: models.StatusPending}, models.RoleTechnician, true, false},
+		{"other technician approves", models.RepairRequest{Status: models.StatusPending}, models.RoleTechnician, false, true},
+		{"approver requester rejects", models.RepairRequest{Status: models.StatusRejected, RejectionReason: "Out of budget"}, models.RoleRequester, true, false},
+		{"reject without reason", models.RepairRequest{Status: models.StatusRejected}, models.RoleAdmin, false, true},
+		// Decisions can't skip the queue, even for admins
+		{"approve straight to in progress", models.RepairRequest{Status: models.StatusInProgress}, models.RoleAdmin, true, true},
+	}
+	service := NewWorkflowService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateApproval(models.StatusAwaitingApproval, &tt.request, tt.role, tt.approver)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateApproval = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Requests that are no longer awaiting approval have no approval edges
+	request := models.RepairRequest{Status: models.StatusRejected, RejectionReason: "No"}
+	if err := service.ValidateApproval(models.StatusPending, &request, models.RoleAdmin, true); err == nil {
+		t.Error("ValidateApproval from pending succeeded")
+	}
+}
+
+func TestValidateStatusFields(t *testing.T) {
+	technicianID := uint(2)
+	now := time.Now()
+	tests := []struct {
+		name        string
+		request     models.RepairRequest
+		wantMissing []string
+	}{
+		{"pending needs nothing", models.RepairRequest{Status: models.StatusPending}, nil},
+		{"in progress keeps technician", models.RepairRequest{Status: models.StatusInProgress, TechnicianID: &technicianID}, nil},
+		{"in progress without technician", models.RepairRequest{Status: models.StatusInProgress}, []string{"technicianId"}},
+		{"waiting part without technician", models.RepairRequest{Status: models.StatusWaitingPart}, []string{"technicianId"}},
+		{"completed", models.RepairRequest{Status: models.StatusCompleted, TechnicianID: &technicianID, CompletedAt: &now}, nil},
+		{"completed without anything", models.RepairRequest{Status: models.StatusCompleted}, []string{"technicianId", "completedAt"}},
+		{"rejected with cleared reason", models.RepairRequest{Status: models.StatusRejected}, []string{"rejectionReason"}},
+	}
+	service := NewWorkflowService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.ValidateStatusFields(&tt.request)
+			if tt.wantMissing == nil {
+				if err != nil {
+					t.Fatalf("ValidateStatusFields = %v, want nil", err)
+				}
+				return
+			}
+			var transitionErr *TransitionError
+			if !errors.As(err, &transitionErr) {
+				t.Fatalf("ValidateStatusFields = %v, want TransitionError", err)
+			}
+			if !reflect.DeepEqual(transitionErr.Missing, tt.wantMissing) {
+				t.Errorf("missing = %v, want %v", transitionErr.Missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func TestAllowedTransitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     models.RepairStatus
+		role     models.UserRole
+		approver bool
+		want     []models.RepairStatus
+	}{
+		{"admin from pending", models.StatusPending, models.RoleAdmin, false, []models.RepairStatus{models.StatusInProgress, models.StatusRejected}},
+		{"technician from pending", models.StatusPending, models.RoleTechnician, false, []models.RepairStatus{models.StatusInProgress}},
+		{"requester from pending", models.StatusPending, models.RoleRequester, false, []models.RepairStatus{}},
+		{"technician from in progress", models.StatusInProgress, models.RoleTechnician, false, []models.RepairStatus{models.StatusWaitingPart, models.StatusCompleted}},
+		{"requester from completed", models.StatusCompleted, models.RoleRequester, false, []models.RepairStatus{models.StatusPending}},
+		{"requester from rejected", models.StatusRejected, models.RoleRequester, false, []models.RepairStatus{models.StatusAwaitingApproval}},
+		{"admin awaiting approval", models.StatusAwaitingApproval, models.RoleAdmin, false, []models.RepairStatus{models.StatusPending, models.StatusRejected}},
+		{"approver awaiting approval", models.StatusAwaitingApproval, models.RoleTechnician, true, []models.RepairStatus{models.StatusPending, models.StatusRejected}},
+		{"technician awaiting approval", models.StatusAwaitingApproval, models.RoleTechnician, false, []models.RepairStatus{}},
+		// Being an approver opens only the approval edges
+		{"approver requester from pending", models.StatusPending, models.RoleRequester, true, []models.RepairStatus{}},
+	}
+	service := NewWorkflowService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []models.RepairStatus{}
+			for _, transition := range service.AllowedTransitions(tt.from, tt.role, tt.approver) {
+				if transition.From != tt.from {
+					t.Errorf("edge %s -> %s leaves the wrong status", transition.From, transition.To)
+				}
+				got = append(got, transition.To)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AllowedTransitions = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
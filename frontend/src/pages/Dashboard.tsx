@@ -74,6 +74,7 @@ const Dashboard: React.FC = () => {
 
     const getStatusText = (status: string) => {
         switch (status) {
+            case 'awaiting_approval': return 'รออนุมัติ';
             case 'pending': return 'รอดำเนินการ';
             case 'in_progress': return 'กำลังดำเนินการ';
             case 'waiting_part': return 'รออะไหล่';
@@ -52,7 +52,7 @@ const RepairRequestDetail: React.FC = () => {
         status: '',
         technicianId: '',
         rejectionReason: '',
-        cost: '',
+        laborCost: '',
         priority: '',
     });
 
@@ -94,7 +94,7 @@ const RepairRequestDetail: React.FC = () => {
                 status: request.status,
                 technicianId: request.technicianId?.toString() || '',
                 rejectionReason: request.rejectionReason || '',
-                cost: request.cost?.toString() || '',
+                laborCost: request.laborCost?.toString() || '',
                 priority: request.priority,
             });
             setEditDialogOpen(true);
@@ -116,25 +116,35 @@ const RepairRequestDetail: React.FC = () => {
 
             if (editFormData.technicianId && Number(editFormData.technicianId) !== request.technicianId) {
                 updateData.technicianId = Number(editFormData.technicianId);
+            } else if (!editFormData.technicianId && request.technicianId) {
+                updateData.technicianId = null;
             }
 
             if (editFormData.rejectionReason !== request.rejectionReason) {
                 updateData.rejectionReason = editFormData.rejectionReason;
             }
 
-            if (editFormData.cost && Number(editFormData.cost) !== request.cost) {
-                updateData.cost = Number(editFormData.cost);
+            if (editFormData.laborCost !== '' && Number(editFormData.laborCost) !== (request.laborCost || 0)) {
+                updateData.laborCost = Number(editFormData.laborCost);
             }
 
             if (editFormData.priority !== request.priority) {
                 updateData.priority = editFormData.priority;
             }
 
-            await repairRequestAPI.update(request.ID, updateData);
+            updateData.version = request.version;
+
+            await repairRequestAPI.patch(request.ID, updateData);
             setSuccess('อัพเดทข้อมูลสำเร็จ');
             setEditDialogOpen(false);
             fetchRepairRequest(); // Reload data
         } catch (err: any) {
+            if (err.response?.status === 409 && err.response?.data?.current) {
+                // Someone else changed the request; show their version so the user can re-apply
+                setRequest(err.response.data.current);
+                setError('ข้อมูลถูกแก้ไขโดยผู้ใช้อื่น กรุณาตรวจสอบข้อมูลล่าสุดแล้วบันทึกอีกครั้ง');
+                return;
+            }
             setError(err.response?.data?.error || 'เกิดข้อผิดพลาดในการอัพเดท');
         } finally {
             setUpdateLoading(false);
@@ -164,6 +174,7 @@ const RepairRequestDetail: React.FC = () => {
 
     const getStatusText = (status: string) => {
         switch (status) {
+            case 'awaiting_approval': return 'รออนุมัติ';
             case 'pending': return 'รอดำเนินการ';
             case 'in_progress': return 'กำลังดำเนินการ';
             case 'waiting_part': return 'รออะไหล่';
@@ -455,10 +466,10 @@ const RepairRequestDetail: React.FC = () => {
 
                         <TextField
                             fullWidth
-                            label="ค่าใช้จ่าย (บาท)"
+                            label="ค่าแรง (บาท)"
                             type="number"
-                            value={editFormData.cost}
-                            onChange={(e) => setEditFormData(prev => ({ ...prev, cost: e.target.value }))}
+                            value={editFormData.laborCost}
+                            onChange={(e) => setEditFormData(prev => ({ ...prev, laborCost: e.target.value }))}
                         />
 
                         <TextField
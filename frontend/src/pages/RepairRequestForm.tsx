@@ -155,23 +155,12 @@ const RepairRequestForm: React.FC = () => {
             setLoading(true);
             setError(null);
 
-            // Get current user from localStorage
-            const userStr = localStorage.getItem('user');
-            if (!userStr) {
-                setError('ไม่พบข้อมูลผู้ใช้ กรุณาเข้าสู่ระบบใหม่');
-                return;
-            }
-
-            const user = JSON.parse(userStr);
-
             const requestData = {
                 title: formData.title.trim(),
                 description: formData.description.trim(),
                 location: formData.location.trim(),
                 categoryId: formData.categoryId,
                 priority: formData.priority,
-                requesterId: user.ID,
-                status: 'pending' as const,
                 images: formData.images,
             };
 
@@ -18,6 +18,7 @@ import {
     TableCell,
     TableContainer,
     TableHead,
+    TablePagination,
     TableRow,
     Typography,
 } from '@mui/material';
@@ -29,18 +30,22 @@ import { formatDate } from '../utils/dateUtils';
 const RepairRequestsList: React.FC = () => {
     const navigate = useNavigate();
     const [requests, setRequests] = useState<RepairRequest[]>([]);
+    const [page, setPage] = useState(0); // Zero-based, as TablePagination counts pages
+    const [rowsPerPage, setRowsPerPage] = useState(25);
+    const [total, setTotal] = useState(0);
     const [loading, setLoading] = useState(true);
     const [error, setError] = useState<string | null>(null);
 
     useEffect(() => {
         fetchRepairRequests();
-    }, []);
+    }, [page, rowsPerPage]);
 
     const fetchRepairRequests = async () => {
         try {
             setLoading(true);
-            const response = await repairRequestAPI.getAll();
-            setRequests(response.data);
+            const response = await repairRequestAPI.getAll({ page: page + 1, limit: rowsPerPage });
+            setRequests(response.data.data);
+            setTotal(response.data.pagination.total);
             setError(null);
         } catch (err: any) {
             setError(err.response?.data?.error || 'Failed to fetch repair requests');
@@ -83,6 +88,8 @@ const RepairRequestsList: React.FC = () => {
 
     const getStatusText = (status: string) => {
         switch (status) {
+            case 'awaiting_approval':
+                return 'รออนุมัติ';
             case 'pending':
                 return 'รอดำเนินการ';
             case 'in_progress':
@@ -235,6 +242,20 @@ const RepairRequestsList: React.FC = () => {
                         )}
                     </TableBody>
                 </Table>
+                <TablePagination
+                    component="div"
+                    count={total}
+                    page={page}
+                    rowsPerPage={rowsPerPage}
+                    rowsPerPageOptions={[10, 25, 50, 100]}
+                    onPageChange={(_, newPage) => setPage(newPage)}
+                    onRowsPerPageChange={(e) => {
+                        setRowsPerPage(parseInt(e.target.value, 10));
+                        setPage(0);
+                    }}
+                    labelRowsPerPage="แถวต่อหน้า"
+                    labelDisplayedRows={({ from, to, count }) => `${from}-${to} จาก ${count}`}
+                />
             </TableContainer>
         </Container>
     );
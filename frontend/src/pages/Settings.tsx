@@ -1,4 +1,5 @@
 import {
+    Email as EmailIcon,
     Save as SaveIcon,
     Settings as SettingsIcon,
     Telegram as TelegramIcon,
@@ -35,6 +36,22 @@ interface TelegramSettings {
     notifyOnStatusChange: boolean;
     notifyOnAssignment: boolean;
     notifyOnCompletion: boolean;
+    notifyOnRejection: boolean;
+    botUsername: string;
+    updateMode: 'off' | 'polling' | 'webhook';
+    webhookSecret: string;
+    apiBaseUrl: string;
+    proxyUrl: string;
+}
+
+interface EmailSettings {
+    enabled: boolean;
+    host: string;
+    port: number;
+    username: string;
+    password: string;
+    from: string;
+    tlsMode: 'none' | 'starttls' | 'tls';
 }
 
 interface SystemSettings {
@@ -42,9 +59,15 @@ interface SystemSettings {
     siteDescription: string;
     adminEmail: string;
     autoAssignTechnicians: boolean;
+    autoAssignStrategy: 'round_robin' | 'least_workload' | 'skill_match';
     requireApproval: boolean;
     defaultPriority: 'low' | 'medium' | 'high' | 'urgent';
     maintenanceMode: boolean;
+    maintenanceMessage: string;
+    maintenanceRetryAfter: number;
+    notificationLocale: 'th' | 'en';
+    reopenWindowDays: number;
+    signOffDays: number;
 }
 
 const Settings: React.FC = () => {
@@ -62,16 +85,39 @@ const Settings: React.FC = () => {
         notifyOnStatusChange: true,
         notifyOnAssignment: true,
         notifyOnCompletion: true,
+        notifyOnRejection: true,
+        botUsername: '',
+        updateMode: 'off',
+        webhookSecret: '',
+        apiBaseUrl: '',
+        proxyUrl: '',
+    });
+
+    const [emailSettings, setEmailSettings] = useState<EmailSettings>({
+        enabled: false,
+        host: '',
+        port: 587,
+        username: '',
+        password: '',
+        from: '',
+        tlsMode: 'starttls',
     });
+    const [testingEmail, setTestingEmail] = useState(false);
 
     const [systemSettings, setSystemSettings] = useState<SystemSettings>({
         siteName: 'Repair System',
         siteDescription: 'ระบบแจ้งซ่อมออนไลน์',
         adminEmail: 'admin@example.com',
         autoAssignTechnicians: false,
+        autoAssignStrategy: 'least_workload',
         requireApproval: true,
         defaultPriority: 'medium',
         maintenanceMode: false,
+        maintenanceMessage: 'ระบบอยู่ระหว่างปิดปรับปรุง กรุณาลองใหม่ภายหลัง',
+        maintenanceRetryAfter: 300,
+        notificationLocale: 'th',
+        reopenWindowDays: 7,
+        signOffDays: 7,
     });
 
     useEffect(() => {
@@ -85,6 +131,9 @@ const Settings: React.FC = () => {
             const data = response.data;
 
             setTelegramSettings(data.telegram);
+            if (data.email) {
+                setEmailSettings(data.email);
+            }
             setSystemSettings(data.system);
         } catch (err: any) {
             console.error('Failed to load settings:', err);
@@ -111,6 +160,18 @@ const Settings: React.FC = () => {
                 }
             }
 
+            // Validate email settings if enabled
+            if (emailSettings.enabled) {
+                if (!emailSettings.host.trim()) {
+                    setError('กรุณาระบุ SMTP Host');
+                    return;
+                }
+                if (!emailSettings.from.trim()) {
+                    setError('กรุณาระบุอีเมลผู้ส่ง');
+                    return;
+                }
+            }
+
             // Validate system settings
             if (!systemSettings.siteName.trim()) {
                 setError('กรุณาระบุชื่อเว็บไซต์');
@@ -123,6 +184,7 @@ const Settings: React.FC = () => {
 
             const settingsData = {
                 telegram: telegramSettings,
+                email: emailSettings,
                 system: systemSettings,
             };
 
@@ -162,6 +224,29 @@ const Settings: React.FC = () => {
         }
     };
 
+    const handleTestEmail = async () => {
+        if (!emailSettings.host.trim()) {
+            setError('กรุณาระบุ SMTP Host ก่อน');
+            return;
+        }
+
+        try {
+            setTestingEmail(true);
+            setError(null);
+
+            await settingsAPI.testEmail({
+                ...emailSettings,
+                to: systemSettings.adminEmail,
+            });
+
+            setSuccess(`ส่งอีเมลทดสอบไปที่ ${systemSettings.adminEmail} สำเร็จ!`);
+        } catch (err: any) {
+            setError(err.response?.data?.error || 'เกิดข้อผิดพลาดในการส่งอีเมลทดสอบ');
+        } finally {
+            setTestingEmail(false);
+        }
+    };
+
     if (loading) {
         return (
             <Container maxWidth="lg">
@@ -255,6 +340,88 @@ const Settings: React.FC = () => {
                                         helperText="Chat ID ของกลุ่มหรือผู้ใช้ที่จะรับแจ้งเตือน"
                                     />
 
+                                    <TextField
+                                        fullWidth
+                                        label="Bot Username"
+                                        value={telegramSettings.botUsername}
+                                        onChange={(e) =>
+                                            setTelegramSettings(prev => ({
+                                                ...prev,
+                                                botUsername: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                        helperText="ใช้สร้างลิงก์เชื่อมต่อบัญชี เว้นว่างเพื่อดึงจาก Telegram อัตโนมัติ"
+                                    />
+
+                                    <FormControl fullWidth margin="normal">
+                                        <InputLabel>การรับข้อความจากบอท</InputLabel>
+                                        <Select
+                                            value={telegramSettings.updateMode}
+                                            label="การรับข้อความจากบอท"
+                                            onChange={(e) =>
+                                                setTelegramSettings(prev => ({
+                                                    ...prev,
+                                                    updateMode: e.target.value as TelegramSettings['updateMode']
+                                                }))
+                                            }
+                                        >
+                                            <MenuItem value="off">ปิด</MenuItem>
+                                            <MenuItem value="polling">Long polling</MenuItem>
+                                            <MenuItem value="webhook">Webhook</MenuItem>
+                                        </Select>
+                                        <FormHelperText>
+                                            จำเป็นสำหรับการเชื่อมต่อบัญชีผู้ใช้กับ Telegram
+                                        </FormHelperText>
+                                    </FormControl>
+
+                                    {telegramSettings.updateMode === 'webhook' && (
+                                        <TextField
+                                            fullWidth
+                                            label="Webhook Secret Token"
+                                            value={telegramSettings.webhookSecret}
+                                            onChange={(e) =>
+                                                setTelegramSettings(prev => ({
+                                                    ...prev,
+                                                    webhookSecret: e.target.value
+                                                }))
+                                            }
+                                            margin="normal"
+                                            type="password"
+                                            helperText="ค่า secret_token ที่ใช้ตอนเรียก setWebhook ไปยัง /api/telegram/webhook"
+                                        />
+                                    )}
+
+                                    <TextField
+                                        fullWidth
+                                        label="Bot API URL"
+                                        value={telegramSettings.apiBaseUrl}
+                                        onChange={(e) =>
+                                            setTelegramSettings(prev => ({
+                                                ...prev,
+                                                apiBaseUrl: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                        placeholder="https://api.telegram.org"
+                                        helperText="เว้นว่างเพื่อใช้ api.telegram.org หรือระบุ Local Bot API Server"
+                                    />
+
+                                    <TextField
+                                        fullWidth
+                                        label="Proxy URL"
+                                        value={telegramSettings.proxyUrl}
+                                        onChange={(e) =>
+                                            setTelegramSettings(prev => ({
+                                                ...prev,
+                                                proxyUrl: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                        placeholder="http://proxy.example.com:3128"
+                                        helperText="เว้นว่างเพื่อใช้ HTTPS_PROXY ของเซิร์ฟเวอร์ (รองรับ http, https, socks5)"
+                                    />
+
                                     <Button
                                         variant="outlined"
                                         startIcon={<TestIcon />}
@@ -332,11 +499,153 @@ const Settings: React.FC = () => {
                                             }
                                             label="แจ้งเตือนเมื่องานเสร็จสิ้น"
                                         />
+                                        <FormControlLabel
+                                            control={
+                                                <Switch
+                                                    checked={telegramSettings.notifyOnRejection}
+                                                    onChange={(e) =>
+                                                        setTelegramSettings(prev => ({
+                                                            ...prev,
+                                                            notifyOnRejection: e.target.checked
+                                                        }))
+                                                    }
+                                                />
+                                            }
+                                            label="แจ้งเตือนเมื่อคำขอถูกปฏิเสธ"
+                                        />
                                     </Box>
                                 </>
                             )}
                         </CardContent>
                     </Card>
+
+                    {/* Email Settings */}
+                    <Card sx={{ mt: 3 }}>
+                        <CardHeader
+                            avatar={<EmailIcon color="primary" />}
+                            title="การแจ้งเตือนทางอีเมล"
+                            subheader="ตั้งค่าการส่งอีเมลผ่าน SMTP"
+                        />
+                        <CardContent>
+                            <FormControlLabel
+                                control={
+                                    <Switch
+                                        checked={emailSettings.enabled}
+                                        onChange={(e) =>
+                                            setEmailSettings(prev => ({
+                                                ...prev,
+                                                enabled: e.target.checked
+                                            }))
+                                        }
+                                    />
+                                }
+                                label="เปิดใช้งานการแจ้งเตือนทางอีเมล"
+                                sx={{ mb: 2 }}
+                            />
+
+                            {emailSettings.enabled && (
+                                <>
+                                    <Box sx={{ display: 'flex', gap: 2 }}>
+                                        <TextField
+                                            fullWidth
+                                            label="SMTP Host"
+                                            value={emailSettings.host}
+                                            onChange={(e) =>
+                                                setEmailSettings(prev => ({
+                                                    ...prev,
+                                                    host: e.target.value
+                                                }))
+                                            }
+                                            margin="normal"
+                                        />
+                                        <TextField
+                                            label="Port"
+                                            type="number"
+                                            value={emailSettings.port}
+                                            onChange={(e) =>
+                                                setEmailSettings(prev => ({
+                                                    ...prev,
+                                                    port: parseInt(e.target.value) || 0
+                                                }))
+                                            }
+                                            margin="normal"
+                                            sx={{ width: 140 }}
+                                        />
+                                    </Box>
+
+                                    <FormControl fullWidth margin="normal">
+                                        <InputLabel>การเข้ารหัส</InputLabel>
+                                        <Select
+                                            value={emailSettings.tlsMode}
+                                            label="การเข้ารหัส"
+                                            onChange={(e) =>
+                                                setEmailSettings(prev => ({
+                                                    ...prev,
+                                                    tlsMode: e.target.value as EmailSettings['tlsMode']
+                                                }))
+                                            }
+                                        >
+                                            <MenuItem value="starttls">STARTTLS (พอร์ต 587)</MenuItem>
+                                            <MenuItem value="tls">TLS (พอร์ต 465)</MenuItem>
+                                            <MenuItem value="none">ไม่เข้ารหัส</MenuItem>
+                                        </Select>
+                                    </FormControl>
+
+                                    <TextField
+                                        fullWidth
+                                        label="ชื่อผู้ใช้"
+                                        value={emailSettings.username}
+                                        onChange={(e) =>
+                                            setEmailSettings(prev => ({
+                                                ...prev,
+                                                username: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                    />
+
+                                    <TextField
+                                        fullWidth
+                                        label="รหัสผ่าน"
+                                        value={emailSettings.password}
+                                        onChange={(e) =>
+                                            setEmailSettings(prev => ({
+                                                ...prev,
+                                                password: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                        type="password"
+                                    />
+
+                                    <TextField
+                                        fullWidth
+                                        label="อีเมลผู้ส่ง"
+                                        value={emailSettings.from}
+                                        onChange={(e) =>
+                                            setEmailSettings(prev => ({
+                                                ...prev,
+                                                from: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                        helperText='เช่น "Repair System <noreply@example.com>"'
+                                    />
+
+                                    <Button
+                                        variant="outlined"
+                                        startIcon={<TestIcon />}
+                                        onClick={handleTestEmail}
+                                        disabled={testingEmail}
+                                        fullWidth
+                                        sx={{ mt: 2 }}
+                                    >
+                                        {testingEmail ? <CircularProgress size={20} /> : 'ทดสอบการส่งอีเมล'}
+                                    </Button>
+                                </>
+                            )}
+                        </CardContent>
+                    </Card>
                 </Box>
 
                 {/* System Settings */}
@@ -408,6 +717,53 @@ const Settings: React.FC = () => {
                                 </Select>
                             </FormControl>
 
+                            <FormControl fullWidth margin="normal">
+                                <InputLabel>ภาษาของการแจ้งเตือน</InputLabel>
+                                <Select
+                                    value={systemSettings.notificationLocale}
+                                    label="ภาษาของการแจ้งเตือน"
+                                    onChange={(e) =>
+                                        setSystemSettings(prev => ({
+                                            ...prev,
+                                            notificationLocale: e.target.value as SystemSettings['notificationLocale']
+                                        }))
+                                    }
+                                >
+                                    <MenuItem value="th">ไทย</MenuItem>
+                                    <MenuItem value="en">English</MenuItem>
+                                </Select>
+                            </FormControl>
+
+                            <TextField
+                                fullWidth
+                                label="เปิดงานที่เสร็จแล้วอีกครั้งได้ภายใน (วัน)"
+                                type="number"
+                                value={systemSettings.reopenWindowDays}
+                                onChange={(e) =>
+                                    setSystemSettings(prev => ({
+                                        ...prev,
+                                        reopenWindowDays: Math.max(0, Number(e.target.value))
+                                    }))
+                                }
+                                helperText="0 = ไม่อนุญาตให้เปิดงานใหม่"
+                                margin="normal"
+                            />
+
+                            <TextField
+                                fullWidth
+                                label="ปิดงานอัตโนมัติหากผู้แจ้งไม่ยืนยันภายใน (วัน)"
+                                type="number"
+                                value={systemSettings.signOffDays}
+                                onChange={(e) =>
+                                    setSystemSettings(prev => ({
+                                        ...prev,
+                                        signOffDays: Math.max(0, Number(e.target.value))
+                                    }))
+                                }
+                                helperText="0 = ไม่ปิดอัตโนมัติ"
+                                margin="normal"
+                            />
+
                             <Box sx={{ mt: 2, display: 'flex', flexDirection: 'column' }}>
                                 <Box sx={{ mb: 2 }}>
                                     <FormControlLabel
@@ -427,6 +783,22 @@ const Settings: React.FC = () => {
                                     <FormHelperText>
                                         จะมอบหมายงานให้ช่างที่ว่างที่สุดโดยอัตโนมัติ
                                     </FormHelperText>
+                                    <FormControl fullWidth margin="normal" disabled={!systemSettings.autoAssignTechnicians}>
+                                        <InputLabel>วิธีการมอบหมาย</InputLabel>
+                                        <Select
+                                            value={systemSettings.autoAssignStrategy}
+                                            onChange={(e) =>
+                                                setSystemSettings(prev => ({
+                                                    ...prev,
+                                                    autoAssignStrategy: e.target.value as any
+                                                }))
+                                            }
+                                        >
+                                            <MenuItem value="least_workload">งานค้างน้อยที่สุด</MenuItem>
+                                            <MenuItem value="round_robin">หมุนเวียน</MenuItem>
+                                            <MenuItem value="skill_match">ตามความถนัดของช่าง</MenuItem>
+                                        </Select>
+                                    </FormControl>
                                 </Box>
 
                                 <Box sx={{ mb: 2 }}>
@@ -467,6 +839,31 @@ const Settings: React.FC = () => {
                                     <FormHelperText>
                                         ปิดระบบชั่วคราวเพื่อปรับปรุง (Admin ยังเข้าได้)
                                     </FormHelperText>
+                                    <TextField
+                                        fullWidth
+                                        label="ข้อความแจ้งผู้ใช้ระหว่างปิดปรับปรุง"
+                                        value={systemSettings.maintenanceMessage}
+                                        onChange={(e) =>
+                                            setSystemSettings(prev => ({
+                                                ...prev,
+                                                maintenanceMessage: e.target.value
+                                            }))
+                                        }
+                                        margin="normal"
+                                    />
+                                    <TextField
+                                        fullWidth
+                                        label="ให้ลองใหม่หลังจาก (วินาที)"
+                                        type="number"
+                                        value={systemSettings.maintenanceRetryAfter}
+                                        onChange={(e) =>
+                                            setSystemSettings(prev => ({
+                                                ...prev,
+                                                maintenanceRetryAfter: Number(e.target.value)
+                                            }))
+                                        }
+                                        margin="normal"
+                                    />
                                 </Box>
                             </Box>
                         </CardContent>
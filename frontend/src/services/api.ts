@@ -41,6 +41,9 @@ export interface User {
   role: 'admin' | 'technician' | 'requester';
   phoneNumber?: string;
   telegramId?: string;
+  telegramDms?: boolean;
+  available?: boolean;
+  skills?: Category[];
   createdAt: string;
   updatedAt: string;
 }
@@ -49,10 +52,33 @@ export interface Category {
   ID: number;
   name: string;
   description?: string;
+  approverId?: number | null;
   createdAt: string;
   updatedAt: string;
 }
 
+export interface Approval {
+  ID: number;
+  repairRequestId: number;
+  approverId: number;
+  approver?: User;
+  approved: boolean;
+  note: string;
+  createdAt: string;
+}
+
+export interface Reopening {
+  ID: number;
+  repairRequestId: number;
+  reopenedById: number;
+  reopenedBy?: User;
+  technicianId?: number;
+  technician?: User;
+  completedAt?: string;
+  reason: string;
+  createdAt: string;
+}
+
 export interface RepairRequest {
   ID: number;
   title: string;
@@ -64,16 +90,166 @@ export interface RepairRequest {
   requester?: User;
   technicianId?: number;
   technician?: User;
-  status: 'pending' | 'in_progress' | 'waiting_part' | 'completed' | 'rejected';
+  status: 'awaiting_approval' | 'pending' | 'in_progress' | 'waiting_part' | 'completed' | 'rejected';
   priority: 'low' | 'medium' | 'high' | 'urgent';
   images?: string[];
   completedAt?: string;
   rejectionReason?: string;
+  rejectedById?: number;
+  rejectedBy?: User;
+  rejectedAt?: string;
+  appealReason?: string;
+  appealedAt?: string;
+  resubmittedFromId?: number;
+  reopenCount: number;
+  reopenReason?: string;
+  reopenedAt?: string;
+  reopenings?: Reopening[];
+  signOff: '' | 'accepted' | 'disputed' | 'auto_closed';
+  signedOffAt?: string;
+  rating?: number;
+  ratingComment?: string;
+  disputeReason?: string;
   cost?: number;
+  laborCost?: number;
+  otherCost?: number;
+  partsUsed?: PartUsed[];
+  comments?: Comment[];
+  approvals?: Approval[];
+  version: number;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface Comment {
+  ID: number;
+  repairRequestId: number;
+  userId: number;
+  user?: User;
+  content: string;
+  isInternal: boolean;
   createdAt: string;
   updatedAt: string;
 }
 
+export interface PartUsed {
+  ID: number;
+  repairRequestId: number;
+  name: string;
+  quantity: number;
+  unitPrice: number;
+}
+
+export interface CostBreakdown {
+  parts: PartUsed[];
+  partsSubtotal: number;
+  laborCost: number;
+  otherCost: number;
+  total: number;
+}
+
+export interface RepairRequestEvent {
+  ID: number;
+  repairRequestId: number;
+  actorId?: number | null;
+  actor?: User | null;
+  field: string;
+  oldValue: string;
+  newValue: string;
+  createdAt: string;
+}
+
+export interface StatusTransition {
+  from: RepairRequest['status'];
+  to: RepairRequest['status'];
+  roles: User['role'][];
+  requiredFields: string[];
+  approval?: boolean; // Taken through the approval endpoint, also by the category's approver
+}
+
+export interface Pagination {
+  page: number;
+  limit: number;
+  total: number;
+  totalPages: number;
+}
+
+export interface Paginated<T> {
+  data: T[];
+  pagination: Pagination;
+}
+
+export interface RepairRequestListParams {
+  page?: number;
+  limit?: number;
+  status?: string;
+  priority?: string;
+  categoryId?: number;
+  technicianId?: number | 'unassigned';
+  requesterId?: number;
+  location?: string;
+  createdFrom?: string;
+  createdTo?: string;
+  completedFrom?: string;
+  completedTo?: string;
+  sort?: 'created' | 'updated' | 'priority' | 'age';
+  order?: 'asc' | 'desc';
+}
+
+export interface OutboxMessage {
+  ID: number;
+  event: string;
+  channel: string;
+  payload: string;
+  status: 'pending' | 'sent' | 'dead' | 'cancelled';
+  attempts: number;
+  nextAttemptAt: string;
+  lastError: string;
+  sentAt?: string | null;
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface Webhook {
+  ID: number;
+  name: string;
+  url: string;
+  events: string[];
+  active: boolean;
+  secret?: string; // Only returned when a secret is created or rotated
+  createdAt: string;
+  updatedAt: string;
+}
+
+export interface WebhookDelivery {
+  ID: number;
+  webhookId: number;
+  event: string;
+  payload: string;
+  status: 'pending' | 'succeeded' | 'failed' | 'cancelled';
+  attempts: number;
+  nextAttemptAt: string;
+  responseStatus: number;
+  responseBody: string;
+  lastError: string;
+  deliveredAt?: string | null;
+  createdAt: string;
+}
+
+export interface WebhookInput {
+  name: string;
+  url: string;
+  events: string[];
+  active?: boolean;
+  secret?: string;
+  rotateSecret?: boolean;
+}
+
+export interface NotificationPreferences {
+  telegramLinked: boolean;
+  telegramDms: boolean;
+}
+
 export interface DashboardStats {
   totalRequests: number;
   pendingRequests: number;
@@ -94,11 +270,58 @@ export const authAPI = {
 
 // Repair Request API
 export const repairRequestAPI = {
-  getAll: () => api.get<RepairRequest[]>('/repair-requests'),
+  getAll: (params?: RepairRequestListParams) =>
+    api.get<Paginated<RepairRequest>>('/repair-requests', { params }),
   getById: (id: number) => api.get<RepairRequest>(`/repair-requests/${id}`),
   create: (data: Partial<RepairRequest>) => api.post<RepairRequest>('/repair-requests', data),
   update: (id: number, data: Partial<RepairRequest>) => api.put<RepairRequest>(`/repair-requests/${id}`, data),
+  // JSON Merge Patch: null clears a field
+  patch: (id: number, data: Record<string, unknown>) =>
+    api.patch<RepairRequest>(`/repair-requests/${id}`, data, {
+      headers: { 'Content-Type': 'application/merge-patch+json' },
+    }),
   delete: (id: number) => api.delete(`/repair-requests/${id}`),
+  getHistory: (id: number) =>
+    api.get<{ events: RepairRequestEvent[]; durations: Record<string, number> }>(`/repair-requests/${id}/history`),
+  getTransitions: (id: number) =>
+    api.get<{ status: RepairRequest['status']; transitions: StatusTransition[] }>(`/repair-requests/${id}/transitions`),
+  appeal: (id: number, reason: string) => api.post<RepairRequest>(`/repair-requests/${id}/appeal`, { reason }),
+  resubmit: (id: number, data: Partial<RepairRequest>) =>
+    api.post<RepairRequest>(`/repair-requests/${id}/resubmit`, data),
+  reopen: (id: number, reason: string) => api.post<RepairRequest>(`/repair-requests/${id}/reopen`, { reason }),
+  accept: (id: number, rating: number, comment?: string) =>
+    api.post<RepairRequest>(`/repair-requests/${id}/accept`, { rating, comment }),
+  dispute: (id: number, reason: string) => api.post<RepairRequest>(`/repair-requests/${id}/dispute`, { reason }),
+};
+
+// Comment API
+export const commentAPI = {
+  getAll: (requestId: number) => api.get<Comment[]>(`/repair-requests/${requestId}/comments`),
+  create: (requestId: number, data: { content: string; isInternal?: boolean }) =>
+    api.post<Comment>(`/repair-requests/${requestId}/comments`, data),
+  update: (requestId: number, commentId: number, data: { content: string; isInternal?: boolean }) =>
+    api.put<Comment>(`/repair-requests/${requestId}/comments/${commentId}`, data),
+  delete: (requestId: number, commentId: number) => api.delete(`/repair-requests/${requestId}/comments/${commentId}`),
+};
+
+// Approval API
+export const approvalAPI = {
+  getPending: () => api.get<RepairRequest[]>('/approvals'),
+  decide: (requestId: number, data: { approved: boolean; note?: string }) =>
+    api.post<{ request: RepairRequest; approval: Approval }>(`/repair-requests/${requestId}/approval`, data),
+};
+
+// Parts and cost API
+export const partAPI = {
+  getAll: (requestId: number) => api.get<PartUsed[]>(`/repair-requests/${requestId}/parts`),
+  create: (requestId: number, data: { name: string; quantity: number; unitPrice: number }) =>
+    api.post<CostBreakdown>(`/repair-requests/${requestId}/parts`, data),
+  update: (requestId: number, partId: number, data: { name: string; quantity: number; unitPrice: number }) =>
+    api.put<CostBreakdown>(`/repair-requests/${requestId}/parts/${partId}`, data),
+  delete: (requestId: number, partId: number) => api.delete<CostBreakdown>(`/repair-requests/${requestId}/parts/${partId}`),
+  getCost: (requestId: number) => api.get<CostBreakdown>(`/repair-requests/${requestId}/cost`),
+  updateCost: (requestId: number, data: { laborCost: number; otherCost: number }) =>
+    api.put<CostBreakdown>(`/repair-requests/${requestId}/cost`, data),
 };
 
 // Category API
@@ -117,35 +340,194 @@ export const userAPI = {
   create: (data: Partial<User>) => api.post<User>('/users', data),
   update: (id: number, data: Partial<User>) => api.put<User>(`/users/${id}`, data),
   delete: (id: number) => api.delete(`/users/${id}`),
+  updateAvailability: (id: number, available: boolean) => api.put<User>(`/users/${id}/availability`, { available }),
+  updateSkills: (id: number, categoryIds: number[]) => api.put<User>(`/users/${id}/skills`, { categoryIds }),
+};
+
+// Assignment API
+export const assignmentAPI = {
+  preview: (requestId: number, strategy?: string) =>
+    api.get<{ strategy: string; strategies: string[]; technician: User }>(`/repair-requests/${requestId}/assignment/preview`, { params: { strategy } }),
+  assign: (requestId: number, data: { technicianId?: number; strategy?: string }) =>
+    api.post<RepairRequest>(`/repair-requests/${requestId}/assign`, data),
 };
 
 // Dashboard API
 export const dashboardAPI = {
   getStats: async (): Promise<DashboardStats> => {
-    const response = await repairRequestAPI.getAll();
-    const requests = response.data;
-
-    const stats = {
-      totalRequests: requests.length,
-      pendingRequests: requests.filter(r => r.status === 'pending').length,
-      inProgressRequests: requests.filter(r => r.status === 'in_progress').length,
-      waitingPartRequests: requests.filter(r => r.status === 'waiting_part').length,
-      completedRequests: requests.filter(r => r.status === 'completed').length,
-      rejectedRequests: requests.filter(r => r.status === 'rejected').length,
-      recentRequests: requests
-        .sort((a, b) => new Date(b.createdAt).getTime() - new Date(a.createdAt).getTime())
-        .slice(0, 5),
+    const countByStatus = async (status?: RepairRequest['status']) => {
+      const response = await repairRequestAPI.getAll({ status, limit: 1 });
+      return response.data.pagination.total;
     };
 
-    return stats;
+    const [total, pending, inProgress, waitingPart, completed, rejected, recent] = await Promise.all([
+      countByStatus(),
+      countByStatus('pending'),
+      countByStatus('in_progress'),
+      countByStatus('waiting_part'),
+      countByStatus('completed'),
+      countByStatus('rejected'),
+      repairRequestAPI.getAll({ sort: 'created', order: 'desc', limit: 5 }),
+    ]);
+
+    return {
+      totalRequests: total,
+      pendingRequests: pending,
+      inProgressRequests: inProgress,
+      waitingPartRequests: waitingPart,
+      completedRequests: completed,
+      rejectedRequests: rejected,
+      recentRequests: recent.data.data,
+    };
   },
 };
 
+// Current user API
+export const meAPI = {
+  getNotifications: () => api.get<NotificationPreferences>('/me/notifications'),
+  updateNotifications: (data: { telegramDms: boolean }) =>
+    api.put<NotificationPreferences>('/me/notifications', data),
+  createTelegramLink: () =>
+    api.post<{ code: string; url: string; expiresAt: string }>('/me/telegram/link'),
+  unlinkTelegram: () => api.delete<NotificationPreferences>('/me/telegram'),
+};
+
 // Settings API
+export type NotificationAudience = 'group' | 'admin' | 'requester' | 'technician' | 'subscribers';
+
+export interface NotificationRule {
+  event: string;
+  channel: string;
+  audience: NotificationAudience;
+  enabled: boolean;
+}
+
+export interface NotificationMatrix {
+  events: string[];
+  channels: { channel: string; enabled: boolean }[];
+  rules: NotificationRule[];
+}
+
 export const settingsAPI = {
   getSettings: () => api.get('/settings'),
   updateSettings: (settings: any) => api.put('/settings', settings),
   testTelegram: (testData: { botToken: string; chatId: string }) => api.post('/settings/test-telegram', testData),
+  testEmail: (testData: {
+    host?: string;
+    port?: number;
+    username?: string;
+    password?: string;
+    from?: string;
+    tlsMode?: string;
+    to?: string;
+  }) => api.post('/settings/test-email', testData),
+  getNotificationMatrix: () => api.get<NotificationMatrix>('/settings/notifications'),
+  updateNotificationRules: (rules: NotificationRule[]) =>
+    api.put<NotificationMatrix>('/settings/notifications', { rules }),
+};
+
+// Notification API
+export const notificationAPI = {
+  getOutbox: (params?: { status?: OutboxMessage['status']; channel?: string; limit?: number }) =>
+    api.get<OutboxMessage[]>('/notifications/outbox', { params }),
+  replay: (id: number) => api.post<OutboxMessage>(`/notifications/outbox/${id}/replay`),
+};
+
+// Webhook API
+export const webhookAPI = {
+  getAll: () => api.get<{ webhooks: Webhook[]; events: string[] }>('/webhooks'),
+  create: (data: WebhookInput) => api.post<Webhook>('/webhooks', data),
+  update: (id: number, data: WebhookInput) => api.put<Webhook>(`/webhooks/${id}`, data),
+  delete: (id: number) => api.delete(`/webhooks/${id}`),
+  getDeliveries: (id: number, status?: WebhookDelivery['status']) =>
+    api.get<WebhookDelivery[]>(`/webhooks/${id}/deliveries`, { params: { status } }),
+  redeliver: (id: number, deliveryId: number) =>
+    api.post<WebhookDelivery>(`/webhooks/${id}/deliveries/${deliveryId}/redeliver`),
+};
+
+// Notification template API
+export type NotificationLocale = 'th' | 'en';
+
+export interface NotificationTemplate {
+  ID: number;
+  createdAt: string;
+  updatedAt: string;
+  channel: 'telegram' | 'email';
+  event: string;
+  locale: NotificationLocale;
+  subject: string;
+  body: string;
+  html: string;
+  customized: boolean;
+}
+
+export interface NotificationTemplateInput {
+  subject?: string;
+  body: string;
+  html?: string;
+}
+
+export interface RenderedNotification {
+  subject: string;
+  body: string;
+  html: string;
+}
+
+const templatePath = (channel: string, event: string, locale: NotificationLocale) =>
+  `/notification-templates/${channel}/${event}/${locale}`;
+
+export const notificationTemplateAPI = {
+  getAll: () =>
+    api.get<{
+      templates: NotificationTemplate[];
+      channels: string[];
+      events: string[];
+      locales: NotificationLocale[];
+      locale: NotificationLocale;
+    }>('/notification-templates'),
+  update: (channel: string, event: string, locale: NotificationLocale, data: NotificationTemplateInput) =>
+    api.put<NotificationTemplate>(templatePath(channel, event, locale), data),
+  reset: (channel: string, event: string, locale: NotificationLocale) =>
+    api.delete<NotificationTemplate>(templatePath(channel, event, locale)),
+  preview: (data: Partial<NotificationTemplateInput> & {
+    channel: string;
+    event: string;
+    locale: NotificationLocale;
+    requestId?: number;
+  }) => api.post<RenderedNotification>('/notification-templates/preview', data),
+};
+
+// Report API
+export interface RatingSummary {
+  completed: number;
+  rated: number;
+  averageRating: number | null;
+  distribution: [number, number, number, number, number]; // 1 to 5 stars
+  disputed: number;
+  autoClosed: number;
+  awaiting: number;
+}
+
+export interface TechnicianRating extends RatingSummary {
+  technicianId: number;
+  fullName: string;
+}
+
+export interface CategoryRating extends RatingSummary {
+  categoryId: number;
+  name: string;
+}
+
+export interface ReportPeriodParams {
+  from?: string;
+  to?: string;
+}
+
+export const reportAPI = {
+  getTechnicianRatings: (params?: ReportPeriodParams) =>
+    api.get<{ technicians: TechnicianRating[]; overall: RatingSummary }>('/reports/ratings/technicians', { params }),
+  getCategoryRatings: (params?: ReportPeriodParams) =>
+    api.get<{ categories: CategoryRating[]; overall: RatingSummary }>('/reports/ratings/categories', { params }),
 };
 
 // Upload API